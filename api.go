@@ -1,5 +1,10 @@
 package errors
 
+const (
+	// GenericSafeErrorType is the catalog key for translations of GenericSafeErrorMessage.
+	GenericSafeErrorType ErrorType = "errors.GenericSafeErrorMessage"
+)
+
 var (
 	// GenericSafeErrorMessage denotes the message replacement when exposing unsafe errors via API.
 	GenericSafeErrorMessage string
@@ -32,16 +37,23 @@ func DefaultAPI(message string) APIError {
 }
 
 func (err baseError) API() APIError {
+	return err.LocalizedAPI()
+}
+
+func (err baseError) LocalizedAPI(locales ...string) APIError {
 	suffix := ""
 	if err.flags.track && len(err.trace.id) > 0 {
 		suffix = " [ID " + err.trace.id + "]"
 	}
 
 	if PrintUnsafeErrors {
-		return APIError{err.api.httpCode, err.api.errCode, err.Error() + suffix}
+		return APIError{err.api.httpCode, err.api.errCode, err.localizedString(false, locales) + suffix}
 	}
 	if err.flags.isSafe {
-		return APIError{err.api.httpCode, err.api.errCode, err.SafeString() + suffix}
+		return APIError{err.api.httpCode, err.api.errCode, err.localizedString(true, locales) + suffix}
+	}
+	if msg, ok := Messages.lookup(locales, GenericSafeErrorType, defaultErrCode); ok {
+		return APIError{err.api.httpCode, err.api.errCode, msg + suffix}
 	}
 	return APIError{err.api.httpCode, err.api.errCode, GenericSafeErrorMessage + suffix}
 }
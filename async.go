@@ -0,0 +1,192 @@
+package errors
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// DropPolicy denotes the behavior of an AsyncSink with full queue.
+type DropPolicy int
+
+const (
+	// DropOldest removes the oldest queued record to make room for the new one.
+	DropOldest DropPolicy = iota
+	// DropNewest discards the new record.
+	DropNewest
+	// Block waits until the queue has room for the new record.
+	Block
+)
+
+func (p DropPolicy) String() string {
+	switch p {
+	case DropOldest:
+		return "drop-oldest"
+	case DropNewest:
+		return "drop-newest"
+	case Block:
+		return "block"
+	default:
+		return fmt.Sprintf("DropPolicy(%d)", int(p))
+	}
+}
+
+// AsyncSink writes records to another sink in a background goroutine, so slow log writers do not delay the caller. Records are buffered in a bounded queue. It is safe for concurrent use.
+type AsyncSink struct {
+	sink   Sink
+	size   int
+	policy DropPolicy
+
+	mutex    sync.Mutex
+	notEmpty *sync.Cond
+	notFull  *sync.Cond
+	queue    []Record
+	// busy denotes a record that has been taken from the queue but is not yet written.
+	busy    bool
+	closed  bool
+	idle    []chan struct{}
+	done    chan struct{}
+	dropped int64
+	written int64
+	failed  int64
+}
+
+// NewAsyncSink starts a background goroutine writing to sink with a queue of the given size. Call Close() to stop it.
+func NewAsyncSink(sink Sink, size int, policy DropPolicy) *AsyncSink {
+	if size < 1 {
+		size = 1
+	}
+	s := &AsyncSink{sink: sink, size: size, policy: policy, queue: make([]Record, 0, size), done: make(chan struct{})}
+	s.notEmpty = sync.NewCond(&s.mutex)
+	s.notFull = sync.NewCond(&s.mutex)
+	go s.run()
+	return s
+}
+
+// Write queues the record. Records written after Close() are dropped.
+func (s *AsyncSink) Write(record Record) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for !s.closed && len(s.queue) >= s.size {
+		switch s.policy {
+		case DropNewest:
+			s.dropped++
+			return
+		case Block:
+			s.notFull.Wait()
+		default:
+			s.queue[0] = Record{}
+			s.queue = s.queue[1:]
+			s.dropped++
+		}
+	}
+	if s.closed {
+		s.dropped++
+		return
+	}
+	s.queue = append(s.queue, record)
+	s.notEmpty.Signal()
+}
+
+func (s *AsyncSink) run() {
+	defer close(s.done)
+	for {
+		s.mutex.Lock()
+		for len(s.queue) == 0 {
+			s.notifyIdle()
+			if s.closed {
+				s.mutex.Unlock()
+				return
+			}
+			s.notEmpty.Wait()
+		}
+		record := s.queue[0]
+		s.queue[0] = Record{}
+		s.queue = s.queue[1:]
+		s.busy = true
+		s.notFull.Signal()
+		s.mutex.Unlock()
+
+		ok := s.write(record)
+
+		s.mutex.Lock()
+		s.busy = false
+		if ok {
+			s.written++
+		} else {
+			s.failed++
+		}
+		s.mutex.Unlock()
+	}
+}
+
+// write passes the record to the wrapped sink and returns true on success. Panics of the sink are recovered to keep the queue running and counted by Failed().
+func (s *AsyncSink) write(record Record) (ok bool) {
+	defer func() {
+		if recover() != nil {
+			ok = false
+		}
+	}()
+	s.sink.Write(record)
+	return true
+}
+
+// notifyIdle wakes up all waiting calls to Flush. Must be called with locked mutex.
+func (s *AsyncSink) notifyIdle() {
+	for _, ch := range s.idle {
+		close(ch)
+	}
+	s.idle = nil
+}
+
+// Flush waits until all queued records have been written or the context is done.
+func (s *AsyncSink) Flush(ctx context.Context) error {
+	s.mutex.Lock()
+	if len(s.queue) == 0 && !s.busy {
+		s.mutex.Unlock()
+		return nil
+	}
+	ch := make(chan struct{})
+	s.idle = append(s.idle, ch)
+	s.mutex.Unlock()
+
+	select {
+	case <-ch:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close writes all queued records and stops the background goroutine. Subsequent writes are dropped.
+func (s *AsyncSink) Close() error {
+	s.mutex.Lock()
+	s.closed = true
+	s.notEmpty.Broadcast()
+	s.notFull.Broadcast()
+	s.mutex.Unlock()
+	<-s.done
+	return nil
+}
+
+// Dropped returns the number of records discarded due to a full queue or a closed sink.
+func (s *AsyncSink) Dropped() int64 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.dropped
+}
+
+// Written returns the number of records passed to the underlying sink.
+func (s *AsyncSink) Written() int64 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.written
+}
+
+// Failed returns the number of records lost due to a panic of the underlying sink.
+func (s *AsyncSink) Failed() int64 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.failed
+}
@@ -0,0 +1,190 @@
+package errors
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// collectSink records all written errors. Writes block while the gate is closed.
+type collectSink struct {
+	mutex   sync.Mutex
+	gate    chan struct{}
+	records []Record
+}
+
+func newCollectSink(open bool) *collectSink {
+	s := &collectSink{gate: make(chan struct{})}
+	if open {
+		close(s.gate)
+	}
+	return s
+}
+
+func (s *collectSink) Write(record Record) {
+	<-s.gate
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.records = append(s.records, record)
+}
+
+func (s *collectSink) messages() []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	messages := make([]string, len(s.records))
+	for i, record := range s.records {
+		messages[i] = record.Error.Error()
+	}
+	return messages
+}
+
+func testRecord(i int) Record {
+	return Record{New("record %d").Make().Args(i), SeverityError, time.Now()}
+}
+
+func TestAsyncSinkFlush(t *testing.T) {
+	target := newCollectSink(true)
+	sink := NewAsyncSink(target, 16, Block)
+	defer sink.Close()
+
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < 250; i++ {
+				sink.Write(testRecord(w*250 + i))
+			}
+		}(w)
+	}
+	wg.Wait()
+	assert.NoError(t, sink.Flush(context.Background()))
+	assert.Len(t, target.messages(), 1000, "No records should be lost on flush")
+	assert.Equal(t, int64(1000), sink.Written())
+	assert.Equal(t, int64(0), sink.Dropped())
+	assert.NoError(t, sink.Flush(context.Background()), "Flush of empty sink should return immediately")
+}
+
+func TestAsyncSinkDropNewest(t *testing.T) {
+	target := newCollectSink(false)
+	sink := NewAsyncSink(target, 2, DropNewest)
+	defer sink.Close()
+
+	sink.Write(testRecord(0))
+	waitBusy(t, sink)
+	for i := 1; i < 6; i++ {
+		sink.Write(testRecord(i))
+	}
+	close(target.gate)
+	assert.NoError(t, sink.Flush(context.Background()))
+	assert.Equal(t, []string{"record 0", "record 1", "record 2"}, target.messages())
+	assert.Equal(t, int64(3), sink.Dropped())
+}
+
+func TestAsyncSinkDropOldest(t *testing.T) {
+	target := newCollectSink(false)
+	sink := NewAsyncSink(target, 2, DropOldest)
+	defer sink.Close()
+
+	sink.Write(testRecord(0))
+	waitBusy(t, sink)
+	for i := 1; i < 6; i++ {
+		sink.Write(testRecord(i))
+	}
+	close(target.gate)
+	assert.NoError(t, sink.Flush(context.Background()))
+	assert.Equal(t, []string{"record 0", "record 4", "record 5"}, target.messages())
+	assert.Equal(t, int64(3), sink.Dropped())
+}
+
+func TestAsyncSinkFlushTimeout(t *testing.T) {
+	target := newCollectSink(false)
+	sink := NewAsyncSink(target, 4, Block)
+	sink.Write(testRecord(0))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, sink.Flush(ctx))
+
+	close(target.gate)
+	assert.NoError(t, sink.Close())
+	assert.Equal(t, []string{"record 0"}, target.messages())
+}
+
+func TestAsyncSinkClose(t *testing.T) {
+	target := newCollectSink(true)
+	sink := NewAsyncSink(target, 100, DropNewest)
+	for i := 0; i < 50; i++ {
+		sink.Write(testRecord(i))
+	}
+	assert.NoError(t, sink.Close())
+	assert.Len(t, target.messages(), 50, "Close should write all queued records")
+
+	sink.Write(testRecord(50))
+	assert.Equal(t, int64(1), sink.Dropped())
+	assert.NoError(t, sink.Close())
+}
+
+func TestAsyncSinkPanic(t *testing.T) {
+	target := newCollectSink(true)
+	sink := NewAsyncSink(SinkFunc(func(record Record) {
+		if record.Error.Error() == "record 1" {
+			panic("sink failure")
+		}
+		target.Write(record)
+	}), 4, Block)
+	for i := 0; i < 3; i++ {
+		sink.Write(testRecord(i))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.NoError(t, sink.Flush(ctx), "A panicking sink should not stop the queue")
+	assert.Equal(t, []string{"record 0", "record 2"}, target.messages())
+	assert.Equal(t, int64(2), sink.Written())
+	assert.Equal(t, int64(1), sink.Failed(), "Panicking writes should be counted as failed")
+	assert.NoError(t, sink.Close())
+}
+
+func TestLogSink(t *testing.T) {
+	target := newCollectSink(true)
+	sink := NewAsyncSink(target, 8, Block)
+	LogSink = sink
+	defer func() { LogSink = nil }()
+
+	lb := setLogBuffer()
+	err := New("async").HTTPCode(404).Make()
+	err.ToLog()
+	assert.NoError(t, sink.Close())
+	assert.Equal(t, "", lb.String())
+	if assert.Len(t, target.records, 1) {
+		assert.Equal(t, err, target.records[0].Error)
+		assert.Equal(t, SeverityWarn, target.records[0].Severity)
+	}
+}
+
+func TestLoggerSink(t *testing.T) {
+	lb := setLogBuffer()
+	err := New("lines").Trace().Make()
+	NewLoggerSink(Logger).Write(Record{err, SeverityInfo, time.Now()})
+	assert.Contains(t, lb.String(), fmt.Sprintf("[INFO %s] lines[STACK %s]", err.GetID(), err.GetID()))
+	assert.Contains(t, lb.String(), "[STACK "+err.GetID()+"]")
+}
+
+// waitBusy waits until the background goroutine of the sink has taken the first record.
+func waitBusy(t *testing.T, sink *AsyncSink) {
+	for i := 0; i < 1000; i++ {
+		sink.mutex.Lock()
+		busy := sink.busy
+		sink.mutex.Unlock()
+		if busy {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatal("sink did not start writing")
+}
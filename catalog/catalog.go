@@ -0,0 +1,143 @@
+// Package catalog describes error templates in a serializable form to generate documentation and code.
+package catalog
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/sbreitf1/errors"
+)
+
+var (
+	// ScanError denotes a package that could not be analyzed.
+	ScanError = errors.NewTyped("catalog.ScanError", "Unable to scan package %q")
+	// WriteError denotes a catalog that could not be written.
+	WriteError = errors.NewTyped("catalog.WriteError", "Unable to write catalog")
+)
+
+// Entry describes a single error template.
+type Entry struct {
+	// Name is the name of the template variable.
+	Name string `json:"name"`
+	// Package is the import path of the declaring package.
+	Package string `json:"package,omitempty"`
+	// Type is the error type used for comparison.
+	Type string `json:"type"`
+	// Parents contains the types of all ancestors starting with the direct parent.
+	Parents []string `json:"parents,omitempty"`
+	// Message is the message format string.
+	Message  string `json:"message"`
+	HTTPCode int    `json:"httpCode"`
+	ErrCode  int    `json:"errCode"`
+	Safe     bool   `json:"safe"`
+	// Tags contains all template tags. Tags without value are mapped to nil.
+	Tags map[string]interface{} `json:"tags,omitempty"`
+	// Doc is the documentation comment of the template variable.
+	Doc string `json:"doc,omitempty"`
+	// Source denotes the declaration as "file:line".
+	Source string `json:"source,omitempty"`
+}
+
+// FromTemplates returns catalog entries for the given templates. Name, package, doc and source are not available at runtime and remain empty.
+func FromTemplates(templates []errors.Template) []Entry {
+	entries := make([]Entry, 0, len(templates))
+	for _, t := range templates {
+		e := Entry{
+			Type:     string(t.GetType()),
+			Message:  t.GetMessage(),
+			HTTPCode: t.GetHTTPCode(),
+			ErrCode:  t.GetErrCode(),
+			Safe:     t.IsSafe(),
+		}
+		for _, p := range t.GetParentTypes() {
+			e.Parents = append(e.Parents, string(p))
+		}
+		if tags := t.GetTags(); len(tags) > 0 {
+			e.Tags = tags
+		}
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+// Sort orders entries by error code, error type and name.
+func Sort(entries []Entry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].ErrCode != entries[j].ErrCode {
+			return entries[i].ErrCode < entries[j].ErrCode
+		}
+		if entries[i].Type != entries[j].Type {
+			return entries[i].Type < entries[j].Type
+		}
+		return entries[i].Name < entries[j].Name
+	})
+}
+
+// WriteJSON writes all entries as indented JSON array.
+func WriteJSON(w io.Writer, entries []Entry) errors.Error {
+	if entries == nil {
+		entries = []Entry{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(entries); err != nil {
+		return WriteError.Make().Cause(err)
+	}
+	return nil
+}
+
+// WriteMarkdown writes all entries as Markdown table.
+func WriteMarkdown(w io.Writer, entries []Entry) errors.Error {
+	var sb strings.Builder
+	sb.WriteString("# Error Catalog\n\n")
+	sb.WriteString("| Name | Type | Message | HTTP Code | Error Code | Safe | Tags | Source |\n")
+	sb.WriteString("| --- | --- | --- | --- | --- | --- | --- | --- |\n")
+	for _, e := range entries {
+		name := e.Name
+		if e.Package != "" && name != "" {
+			name = e.Package + "." + name
+		}
+		fmt.Fprintf(&sb, "| %s | `%s` | %s | %d | %d | %s | %s | %s |\n",
+			markdownEscape(name), markdownEscape(e.Type), markdownEscape(e.Message), e.HTTPCode, e.ErrCode, yesNo(e.Safe), markdownEscape(tagsString(e.Tags)), markdownEscape(e.Source))
+	}
+
+	for _, e := range entries {
+		if e.Doc == "" {
+			continue
+		}
+		fmt.Fprintf(&sb, "\n## %s\n\n%s\n", markdownEscape(e.Name), strings.TrimSpace(e.Doc))
+	}
+
+	if _, err := io.WriteString(w, sb.String()); err != nil {
+		return WriteError.Make().Cause(err)
+	}
+	return nil
+}
+
+func tagsString(tags map[string]interface{}) string {
+	names := make([]string, 0, len(tags))
+	for name := range tags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for i, name := range names {
+		if tags[name] != nil {
+			names[i] = fmt.Sprintf("%s=%v", name, tags[name])
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+func markdownEscape(str string) string {
+	return strings.Replace(strings.Replace(str, "|", "\\|", -1), "\n", " ", -1)
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
@@ -0,0 +1,387 @@
+package catalog
+
+import (
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/sbreitf1/errors"
+	"github.com/sbreitf1/errors/internal/gosrc"
+)
+
+// LibraryPath is the import path of the errors package.
+const LibraryPath = "github.com/sbreitf1/errors"
+
+// Warning describes a template declaration that could not be analyzed completely.
+type Warning struct {
+	Source  string
+	Message string
+}
+
+func (w Warning) String() string {
+	return w.Source + ": " + w.Message
+}
+
+// ScanPackage loads the package denoted by an import path or directory and returns all package level templates.
+func ScanPackage(pattern string) ([]Entry, []Warning, errors.Error) {
+	dir := pattern
+	if info, err := os.Stat(pattern); err != nil || !info.IsDir() {
+		wd, _ := os.Getwd()
+		pkg, err := build.Default.Import(pattern, wd, build.FindOnly)
+		if err != nil {
+			return nil, nil, ScanError.Make().Args(pattern).Cause(err)
+		}
+		dir = pkg.Dir
+	}
+	return ScanDir(dir)
+}
+
+// ScanDir parses the Go package in dir (excluding tests) and returns all package level templates declared using New, NewTyped or Derive with their mutator chains.
+func ScanDir(dir string) ([]Entry, []Warning, errors.Error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(info os.FileInfo) bool {
+		return !strings.HasSuffix(info.Name(), "_test.go")
+	}, parser.ParseComments)
+	if err != nil {
+		return nil, nil, ScanError.Make().Args(dir).Cause(err)
+	}
+
+	names := make([]string, 0, len(pkgs))
+	for name := range pkgs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	s := &scanner{fset: fset, dir: dir, pkgPath: gosrc.ImportPath(dir), consts: make(map[string]ast.Expr), decls: make(map[string]*templateDecl), entries: make(map[string]*Entry)}
+	for _, name := range names {
+		s.addPackage(pkgs[name])
+	}
+	return s.scan(), s.warnings, nil
+}
+
+type templateDecl struct {
+	name  string
+	value ast.Expr
+	doc   string
+	// libAlias is the name of the errors package in the declaring file. Empty if the package itself is scanned.
+	libAlias string
+}
+
+type scanner struct {
+	fset     *token.FileSet
+	dir      string
+	pkgPath  string
+	consts   map[string]ast.Expr
+	decls    map[string]*templateDecl
+	order    []string
+	entries  map[string]*Entry
+	warnings []Warning
+}
+
+func (s *scanner) addPackage(pkg *ast.Package) {
+	files := make([]string, 0, len(pkg.Files))
+	for file := range pkg.Files {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+
+	for _, file := range files {
+		f := pkg.Files[file]
+		alias, ok := libAlias(f, s.pkgPath)
+		if !ok {
+			continue
+		}
+
+		for _, decl := range f.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				vs, ok := spec.(*ast.ValueSpec)
+				if !ok {
+					continue
+				}
+				doc := vs.Doc
+				if doc == nil && len(gen.Specs) == 1 {
+					doc = gen.Doc
+				}
+				for i, name := range vs.Names {
+					if i >= len(vs.Values) {
+						break
+					}
+					if gen.Tok == token.CONST {
+						s.consts[name.Name] = vs.Values[i]
+					} else if gen.Tok == token.VAR {
+						s.decls[name.Name] = &templateDecl{name.Name, vs.Values[i], doc.Text(), alias}
+						s.order = append(s.order, name.Name)
+					}
+				}
+			}
+		}
+	}
+}
+
+// libAlias returns the name of the errors package in the given file or false, if the package is not used.
+func libAlias(f *ast.File, pkgPath string) (string, bool) {
+	if pkgPath == LibraryPath {
+		return "", true
+	}
+	for _, imp := range f.Imports {
+		path, _ := strconv.Unquote(imp.Path.Value)
+		if path != LibraryPath {
+			continue
+		}
+		if imp.Name != nil {
+			return imp.Name.Name, imp.Name.Name != "_" && imp.Name.Name != "."
+		}
+		return "errors", true
+	}
+	return "", false
+}
+
+func (s *scanner) scan() []Entry {
+	entries := make([]Entry, 0)
+	for _, name := range s.order {
+		if e := s.resolve(name, nil); e != nil {
+			entries = append(entries, *e)
+		}
+	}
+	return entries
+}
+
+// resolve returns the entry of the named template variable or nil, if it is not a template.
+func (s *scanner) resolve(name string, visiting map[string]bool) *Entry {
+	if e, ok := s.entries[name]; ok {
+		return e
+	}
+	decl, ok := s.decls[name]
+	if !ok || visiting[name] {
+		return nil
+	}
+	if visiting == nil {
+		visiting = make(map[string]bool)
+	}
+	visiting[name] = true
+
+	e := s.evalChain(decl, decl.value, visiting)
+	if e != nil {
+		e.Name = decl.name
+		e.Package = s.pkgPath
+		e.Doc = decl.doc
+		e.Source = gosrc.Position(s.fset, decl.value.Pos(), s.dir)
+	}
+	s.entries[name] = e
+	return e
+}
+
+// evalChain evaluates a template expression like errors.New("foo").API(404, 1).Safe().
+func (s *scanner) evalChain(decl *templateDecl, expr ast.Expr, visiting map[string]bool) *Entry {
+	switch x := expr.(type) {
+	case *ast.ParenExpr:
+		return s.evalChain(decl, x.X, visiting)
+
+	case *ast.Ident:
+		// copy of another template
+		if e := s.resolve(x.Name, visiting); e != nil {
+			c := e.copy()
+			return &c
+		}
+		return nil
+
+	case *ast.CallExpr:
+		if fn, ok := s.libFunc(decl, x.Fun); ok {
+			return s.evalConstructor(fn, x)
+		}
+
+		sel, ok := x.Fun.(*ast.SelectorExpr)
+		if !ok {
+			return nil
+		}
+		base := s.evalChain(decl, sel.X, visiting)
+		if base == nil {
+			return nil
+		}
+		e := base.copy()
+		s.applyMutator(&e, sel.Sel.Name, x)
+		return &e
+	}
+	return nil
+}
+
+// libFunc returns the name of a package level function of the errors package.
+func (s *scanner) libFunc(decl *templateDecl, fun ast.Expr) (string, bool) {
+	switch f := fun.(type) {
+	case *ast.Ident:
+		if decl.libAlias == "" && s.pkgPath == LibraryPath {
+			return f.Name, f.Name == "New" || f.Name == "NewTyped"
+		}
+	case *ast.SelectorExpr:
+		if id, ok := f.X.(*ast.Ident); ok && decl.libAlias != "" && id.Name == decl.libAlias {
+			return f.Sel.Name, f.Sel.Name == "New" || f.Sel.Name == "NewTyped"
+		}
+	}
+	return "", false
+}
+
+func (s *scanner) evalConstructor(fn string, call *ast.CallExpr) *Entry {
+	e := &Entry{HTTPCode: 500, ErrCode: 0}
+	switch fn {
+	case "New":
+		if len(call.Args) > 0 {
+			e.Message = s.stringArg(call, 0)
+			e.Type = e.Message
+		}
+	case "NewTyped":
+		if len(call.Args) > 1 {
+			e.Type = s.stringArg(call, 0)
+			e.Message = s.stringArg(call, 1)
+		}
+	}
+	return e
+}
+
+func (s *scanner) applyMutator(e *Entry, method string, call *ast.CallExpr) {
+	switch method {
+	case "Safe":
+		e.Safe = true
+	case "Msg":
+		if len(call.Args) > 0 {
+			e.Message = s.stringArg(call, 0)
+		}
+	case "API":
+		if len(call.Args) > 1 {
+			e.HTTPCode = s.intArg(call, 0)
+			e.ErrCode = s.intArg(call, 1)
+			e.Safe = true
+		}
+	case "HTTPCode":
+		if len(call.Args) > 0 {
+			e.HTTPCode = s.intArg(call, 0)
+		}
+	case "ErrCode":
+		if len(call.Args) > 0 {
+			e.ErrCode = s.intArg(call, 0)
+		}
+	case "Tag":
+		if len(call.Args) > 0 {
+			e.setTag(s.stringArg(call, 0), nil)
+		}
+	case "TagStr":
+		if len(call.Args) > 1 {
+			e.setTag(s.stringArg(call, 0), s.stringArg(call, 1))
+		}
+	case "TagInt":
+		if len(call.Args) > 1 {
+			e.setTag(s.stringArg(call, 0), s.intArg(call, 1))
+		}
+	case "Derive":
+		if len(call.Args) > 0 {
+			e.Parents = append([]string{e.Type}, e.Parents...)
+			e.Type = s.stringArg(call, 0)
+		}
+	case "Qualify":
+		e.Type = s.pkgPath + "." + e.Type
+	}
+}
+
+func (s *scanner) stringArg(call *ast.CallExpr, i int) string {
+	if str, ok := s.constValue(call.Args[i], nil).(string); ok {
+		return str
+	}
+	s.warn(call.Args[i].Pos(), "unable to resolve string argument of %s", callName(call))
+	return ""
+}
+
+func (s *scanner) intArg(call *ast.CallExpr, i int) int {
+	if val, ok := s.constValue(call.Args[i], nil).(int); ok {
+		return val
+	}
+	s.warn(call.Args[i].Pos(), "unable to resolve integer argument of %s", callName(call))
+	return 0
+}
+
+// constValue evaluates literals and references to package level constants. Returns nil for all other expressions.
+func (s *scanner) constValue(expr ast.Expr, visiting map[string]bool) interface{} {
+	switch x := expr.(type) {
+	case *ast.ParenExpr:
+		return s.constValue(x.X, visiting)
+	case *ast.BasicLit:
+		switch x.Kind {
+		case token.STRING:
+			str, err := strconv.Unquote(x.Value)
+			if err != nil {
+				return nil
+			}
+			return str
+		case token.INT:
+			val, err := strconv.ParseInt(x.Value, 0, 64)
+			if err != nil {
+				return nil
+			}
+			return int(val)
+		}
+	case *ast.Ident:
+		if c, ok := s.consts[x.Name]; ok && !visiting[x.Name] {
+			if visiting == nil {
+				visiting = make(map[string]bool)
+			}
+			visiting[x.Name] = true
+			return s.constValue(c, visiting)
+		}
+	case *ast.BinaryExpr:
+		left, right := s.constValue(x.X, visiting), s.constValue(x.Y, visiting)
+		if x.Op == token.ADD {
+			if l, ok := left.(string); ok {
+				if r, ok := right.(string); ok {
+					return l + r
+				}
+			}
+			if l, ok := left.(int); ok {
+				if r, ok := right.(int); ok {
+					return l + r
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (s *scanner) warn(pos token.Pos, msg string, args ...interface{}) {
+	s.warnings = append(s.warnings, Warning{gosrc.Position(s.fset, pos, s.dir), fmt.Sprintf(msg, args...)})
+}
+
+func callName(call *ast.CallExpr) string {
+	if sel, ok := call.Fun.(*ast.SelectorExpr); ok {
+		return sel.Sel.Name + "()"
+	}
+	if id, ok := call.Fun.(*ast.Ident); ok {
+		return id.Name + "()"
+	}
+	return "call"
+}
+
+func (e Entry) copy() Entry {
+	c := e
+	c.Parents = append([]string(nil), e.Parents...)
+	if e.Tags != nil {
+		c.Tags = make(map[string]interface{}, len(e.Tags))
+		for tag, val := range e.Tags {
+			c.Tags[tag] = val
+		}
+	}
+	return c
+}
+
+func (e *Entry) setTag(tag string, value interface{}) {
+	if e.Tags == nil {
+		e.Tags = make(map[string]interface{})
+	}
+	e.Tags[tag] = value
+}
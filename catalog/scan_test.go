@@ -0,0 +1,88 @@
+package catalog
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sbreitf1/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScanDir(t *testing.T) {
+	entries, warnings, err := ScanDir("testdata/storage")
+	errors.AssertNil(t, err)
+	assert.Len(t, warnings, 1)
+	assert.Equal(t, "storage/storage.go:24", warnings[0].Source)
+
+	byName := make(map[string]Entry)
+	for _, e := range entries {
+		byName[e.Name] = e
+	}
+	assert.Len(t, byName, 5)
+
+	notFound := byName["NotFoundError"]
+	assert.Equal(t, "github.com/sbreitf1/errors/catalog/testdata/storage", notFound.Package)
+	assert.Equal(t, "storage.NotFound", notFound.Type)
+	assert.Equal(t, "Resource %s not found", notFound.Message)
+	assert.Equal(t, 404, notFound.HTTPCode)
+	assert.Equal(t, 10, notFound.ErrCode)
+	assert.True(t, notFound.Safe)
+	assert.Equal(t, "NotFoundError denotes a missing resource.\n", notFound.Doc)
+	assert.Equal(t, "storage/storage.go:14", notFound.Source)
+
+	userNotFound := byName["UserNotFoundError"]
+	assert.Equal(t, "storage.UserNotFound", userNotFound.Type)
+	assert.Equal(t, []string{"storage.NotFound"}, userNotFound.Parents)
+	assert.Equal(t, "Resource %s not found", userNotFound.Message)
+	assert.Equal(t, 11, userNotFound.ErrCode)
+	assert.Equal(t, map[string]interface{}{"user": nil}, userNotFound.Tags)
+	assert.Nil(t, notFound.Tags)
+
+	internal := byName["InternalError"]
+	assert.Equal(t, "Internal | storage error", internal.Type)
+	assert.Equal(t, 503, internal.HTTPCode)
+	assert.False(t, internal.Safe)
+	assert.Equal(t, map[string]interface{}{"component": "db"}, internal.Tags)
+
+	assert.Equal(t, "github.com/sbreitf1/errors/catalog/testdata/storage.qualified", byName["QualifiedError"].Type)
+}
+
+func TestScanLibrary(t *testing.T) {
+	entries, _, err := ScanDir("..")
+	errors.AssertNil(t, err)
+
+	found := false
+	for _, e := range entries {
+		if e.Name == "CatalogError" {
+			found = true
+			assert.Equal(t, string(errors.CatalogError.GetType()), e.Type)
+			assert.Equal(t, errors.CatalogError.GetMessage(), e.Message)
+		}
+	}
+	assert.True(t, found)
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	entries, _, _ := ScanDir("testdata/storage")
+	Sort(entries)
+	var sb strings.Builder
+	errors.AssertNil(t, WriteMarkdown(&sb, entries))
+	md := sb.String()
+	assert.True(t, strings.Contains(md, "| `storage.NotFound` | Resource %s not found | 404 | 10 | yes |  | storage/storage.go:14 |"))
+	assert.True(t, strings.Contains(md, "Internal \\| storage error"))
+	assert.True(t, strings.Contains(md, "## UserNotFoundError\n\nUserNotFoundError denotes a missing user.\n"))
+}
+
+func TestFromTemplates(t *testing.T) {
+	tmpl := errors.NewTyped("storage.NotFound", "Resource %s not found").API(404, 10)
+	entries := FromTemplates([]errors.Template{tmpl.Derive("storage.UserNotFound").Tag("user")})
+	assert.Equal(t, []Entry{{
+		Type:     "storage.UserNotFound",
+		Parents:  []string{"storage.NotFound"},
+		Message:  "Resource %s not found",
+		HTTPCode: 404,
+		ErrCode:  10,
+		Safe:     true,
+		Tags:     map[string]interface{}{"user": nil},
+	}}, entries)
+}
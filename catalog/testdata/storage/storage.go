@@ -0,0 +1,32 @@
+package storage
+
+import (
+	errs "github.com/sbreitf1/errors"
+)
+
+const (
+	codeNotFound = 10
+	notFoundType = "storage.NotFound"
+)
+
+var (
+	// NotFoundError denotes a missing resource.
+	NotFoundError = errs.NewTyped(notFoundType, "Resource %s not found").API(404, codeNotFound).Register()
+	// UserNotFoundError denotes a missing user.
+	UserNotFoundError = NotFoundError.Derive("storage.UserNotFound").ErrCode(11).Tag("user")
+	// InternalError is not safe.
+	InternalError = errs.New("Internal | storage error").Trace().HTTPCode(503).TagStr("component", "db")
+	// QualifiedError has a package qualified type.
+	QualifiedError = errs.New("qualified").Qualify().Safe()
+)
+
+// DynamicError uses a message that cannot be resolved statically.
+var DynamicError = errs.New(dynamicMessage())
+
+func dynamicMessage() string {
+	return "dynamic"
+}
+
+func local() {
+	_ = errs.New("not a package level template")
+}
@@ -0,0 +1,81 @@
+// Command errdoc generates a catalog of all error templates declared in Go packages.
+//
+// Usage:
+//
+//	errdoc [-format markdown|json|openapi] [-problem] [-o file] [packages]
+//
+// Packages can be denoted by import path or directory (also recursively like ./...) and default to the current directory. Templates are detected statically by analyzing package level declarations using errors.New, errors.NewTyped and Derive together with their mutator chains. The openapi format generates OpenAPI 3 components with an error schema and a response object per HTTP status code.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/sbreitf1/errors"
+	"github.com/sbreitf1/errors/catalog"
+	"github.com/sbreitf1/errors/internal/gosrc"
+	"github.com/sbreitf1/errors/openapi"
+)
+
+var (
+	formatFlag  = flag.String("format", "markdown", "output format (markdown, json or openapi)")
+	problemFlag = flag.Bool("problem", false, "use RFC 7807 problem details in openapi format")
+	outFlag     = flag.String("o", "", "output file (default stdout)")
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: errdoc [flags] [packages]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if err := run(flag.Args()); err != nil {
+		fmt.Fprintf(os.Stderr, "errdoc: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(patterns []string) errors.Error {
+	if len(patterns) == 0 {
+		patterns = []string{"."}
+	}
+
+	patterns = gosrc.ExpandPatterns(patterns)
+
+	var entries []catalog.Entry
+	for _, pattern := range patterns {
+		pkgEntries, warnings, err := catalog.ScanPackage(pattern)
+		if err != nil {
+			return err
+		}
+		for _, w := range warnings {
+			fmt.Fprintf(os.Stderr, "errdoc: warning: %v\n", w)
+		}
+		entries = append(entries, pkgEntries...)
+	}
+	catalog.Sort(entries)
+
+	var w io.Writer = os.Stdout
+	if *outFlag != "" {
+		f, err := os.Create(*outFlag)
+		if err != nil {
+			return catalog.WriteError.Make().Cause(err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	switch *formatFlag {
+	case "markdown", "md":
+		return catalog.WriteMarkdown(w, entries)
+	case "json":
+		return catalog.WriteJSON(w, entries)
+	case "openapi":
+		return openapi.Generate(entries, openapi.Options{ProblemDetails: *problemFlag}).WriteJSON(w)
+	default:
+		return errors.ArgumentError.Make().StrCause("unknown format %q", *formatFlag)
+	}
+}
@@ -0,0 +1,57 @@
+// Command errmigrate rewrites call sites of fmt.Errorf, the standard errors package and github.com/pkg/errors to use this package.
+//
+// Usage:
+//
+//	errmigrate [-d] [packages]
+//
+// Packages can be denoted by import path or directory (also recursively like ./...) and default to the current directory. Package level sentinels are converted to templates, references to them are instantiated using Make() and comparisons are replaced by InstanceOf. Wrapping using %w and pkg/errors is converted to Expand or Cause. Files are modified in place unless -d is given, which only prints a diff of all changes. Call sites that could not be converted are printed to stderr.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/sbreitf1/errors"
+	"github.com/sbreitf1/errors/internal/gosrc"
+	"github.com/sbreitf1/errors/migrate"
+)
+
+var (
+	diffFlag = flag.Bool("d", false, "dry run: print diffs instead of modifying files")
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: errmigrate [flags] [packages]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if err := run(flag.Args()); err != nil {
+		fmt.Fprintf(os.Stderr, "errmigrate: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(patterns []string) errors.Error {
+	if len(patterns) == 0 {
+		patterns = []string{"."}
+	}
+
+	results, err := migrate.Packages(gosrc.ExpandPatterns(patterns))
+	if err != nil {
+		return err
+	}
+	for _, result := range results {
+		for _, r := range result.Reports {
+			fmt.Fprintf(os.Stderr, "errmigrate: not converted: %v\n", r)
+		}
+		if *diffFlag {
+			fmt.Print(result.Diff())
+		} else if err := result.Write(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,274 @@
+package errors
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// Frame represents a program counter inside a stack frame. It has the same shape and formatting as Frame of github.com/pkg/errors.
+type Frame uintptr
+
+// pc returns the program counter for this frame. Multiple frames may have the same PC value.
+func (f Frame) pc() uintptr {
+	return uintptr(f) - 1
+}
+
+func (f Frame) location() (string, string, int) {
+	fn := runtime.FuncForPC(f.pc())
+	if fn == nil {
+		return "unknown", "unknown", 0
+	}
+	file, line := fn.FileLine(f.pc())
+	return fn.Name(), file, line
+}
+
+// Format formats the frame according to the fmt.Formatter interface:
+//
+//	%s    source file
+//	%d    source line
+//	%n    function name
+//	%v    equivalent to %s:%d
+//	%+s   function name and full path of source file separated by \n\t
+//	%+v   equivalent to %+s:%d
+func (f Frame) Format(s fmt.State, verb rune) {
+	name, file, line := f.location()
+	switch verb {
+	case 's':
+		if s.Flag('+') {
+			io.WriteString(s, name)
+			io.WriteString(s, "\n\t")
+			io.WriteString(s, file)
+		} else {
+			io.WriteString(s, path.Base(file))
+		}
+	case 'd':
+		io.WriteString(s, strconv.Itoa(line))
+	case 'n':
+		io.WriteString(s, funcName(name))
+	case 'v':
+		f.Format(s, 's')
+		io.WriteString(s, ":")
+		f.Format(s, 'd')
+	}
+}
+
+// MarshalText formats a frame as "function file:line".
+func (f Frame) MarshalText() ([]byte, error) {
+	name, file, line := f.location()
+	if name == "unknown" {
+		return []byte(name), nil
+	}
+	return []byte(fmt.Sprintf("%s %s:%d", name, file, line)), nil
+}
+
+// funcName removes the path prefix of a function name.
+func funcName(name string) string {
+	name = name[strings.LastIndex(name, "/")+1:]
+	return name[strings.Index(name, ".")+1:]
+}
+
+// StackTrace is a stack of frames from innermost (newest) to outermost (oldest). It has the same shape and formatting as StackTrace of github.com/pkg/errors.
+type StackTrace []Frame
+
+// Format formats the stack of frames according to the fmt.Formatter interface:
+//
+//	%s	lists source files for each frame in the stack
+//	%v	lists the source file and line number for each frame in the stack
+//	%+v   prints function name, source file and line number for each frame in the stack
+func (st StackTrace) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		switch {
+		case s.Flag('+'):
+			for _, f := range st {
+				io.WriteString(s, "\n")
+				f.Format(s, verb)
+			}
+		case s.Flag('#'):
+			fmt.Fprintf(s, "%#v", []Frame(st))
+		default:
+			st.formatSlice(s, verb)
+		}
+	case 's':
+		st.formatSlice(s, verb)
+	}
+}
+
+func (st StackTrace) formatSlice(s fmt.State, verb rune) {
+	io.WriteString(s, "[")
+	for i, f := range st {
+		if i > 0 {
+			io.WriteString(s, " ")
+		}
+		f.Format(s, verb)
+	}
+	io.WriteString(s, "]")
+}
+
+// callers returns the program counters of the calling stack and skips the given number of additional frames.
+func callers(depth int) []uintptr {
+	var pcs [64]uintptr
+	n := runtime.Callers(depth+2, pcs[:])
+	return append([]uintptr(nil), pcs[:n]...)
+}
+
+// maxUnwrapDepth limits the traversal of cause chains of foreign errors.
+const maxUnwrapDepth = 100
+
+// foreignStack returns the program counters of the innermost error in the cause chain that provides a stack trace. Chains are traversed using Unwrap() and Cause() like github.com/pkg/errors.
+func foreignStack(err error) ([]uintptr, bool) {
+	var origin []uintptr
+	for i := 0; err != nil && i < maxUnwrapDepth; i++ {
+		if pcs, ok := stackOf(err); ok {
+			origin = pcs
+		}
+		switch e := err.(type) {
+		case interface{ Unwrap() error }:
+			err = e.Unwrap()
+		case interface{ Cause() error }:
+			err = e.Cause()
+		default:
+			err = nil
+		}
+	}
+	return origin, origin != nil
+}
+
+// stackOf returns the program counters of errors providing a method Callers() []uintptr or StackTrace() that returns a slice of uintptr frames like github.com/pkg/errors.
+func stackOf(err error) ([]uintptr, bool) {
+	if c, ok := err.(interface{ Callers() []uintptr }); ok {
+		pcs := c.Callers()
+		return pcs, len(pcs) > 0
+	}
+
+	method := reflect.ValueOf(err).MethodByName("StackTrace")
+	if !method.IsValid() {
+		return nil, false
+	}
+	t := method.Type()
+	if t.NumIn() != 0 || t.NumOut() != 1 || t.Out(0).Kind() != reflect.Slice || t.Out(0).Elem().Kind() != reflect.Uintptr {
+		return nil, false
+	}
+	st := method.Call(nil)[0]
+	if st.Len() == 0 {
+		return nil, false
+	}
+	pcs := make([]uintptr, st.Len())
+	for i := range pcs {
+		pcs[i] = uintptr(st.Index(i).Uint())
+	}
+	return pcs, true
+}
+
+// formatStack returns the stack trace text for program counters and the frame of the wrap site, if the stack originates from a wrapped error.
+func formatStack(pcs []uintptr, wrapSite uintptr) string {
+	return strings.TrimPrefix(fmt.Sprintf("%+v", toStackTrace(pcs)), "\n") + formatWrapSite(wrapSite)
+}
+
+func formatWrapSite(wrapSite uintptr) string {
+	if wrapSite == 0 {
+		return ""
+	}
+	return fmt.Sprintf("\nwrapped at %+v", Frame(wrapSite))
+}
+
+func toStackTrace(pcs []uintptr) StackTrace {
+	if len(pcs) == 0 {
+		return nil
+	}
+	st := make(StackTrace, len(pcs))
+	for i, pc := range pcs {
+		st[i] = Frame(pc)
+	}
+	return st
+}
+
+// StackTrace returns the call stack captured on instantiation or the origin stack of a wrapped error. This method is compatible with the StackTrace() method of github.com/pkg/errors for tools detecting it by reflection.
+func (err baseError) StackTrace() StackTrace {
+	return toStackTrace(err.trace.stack)
+}
+
+// Unwrap returns the cause of the error for errors.Is and errors.As of the standard library. Errors created by Wrap return the original error.
+func (err baseError) Unwrap() error {
+	if err.content.cause == nil {
+		return err.content.foreign
+	}
+	return err.content.cause
+}
+
+// Format implements fmt.Formatter. The verb %+v prints the error message followed by the stack traces of the error and all traced causes, like errors of github.com/pkg/errors.
+func (err baseError) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			io.WriteString(s, err.Error())
+			if stack := err.renderStack(); stack != "" {
+				io.WriteString(s, "\n")
+				io.WriteString(s, stack)
+			}
+			return
+		}
+		if s.Flag('#') {
+			type plain baseError
+			fmt.Fprintf(s, "%#v", plain(err))
+			return
+		}
+		io.WriteString(s, err.Error())
+	case 's':
+		io.WriteString(s, err.Error())
+	case 'q':
+		fmt.Fprintf(s, "%q", err.Error())
+	}
+}
+
+type causer struct {
+	err Error
+}
+
+// Compat returns err as plain error that implements Cause() error of github.com/pkg/errors in addition to StackTrace(), Unwrap() and %+v formatting. The cause method can not be provided by Error, because Cause(error) is used to set the cause. Wrap, ToRequest, InstanceOf, KindOf and AreEqual treat the result like err. Returns nil if err is nil.
+func Compat(err Error) error {
+	if err == nil {
+		return nil
+	}
+	return causer{err}
+}
+
+func (c causer) Error() string {
+	return c.err.Error()
+}
+
+// Cause returns the cause of the error or the error itself, if no cause is set, so errors.Cause of github.com/pkg/errors stops at the innermost Error. The original error of wrapped foreign errors is returned as is.
+func (c causer) Cause() error {
+	switch cause := c.err.Unwrap().(type) {
+	case nil:
+		return c.err
+	case Error:
+		return Compat(cause)
+	default:
+		return cause
+	}
+}
+
+// Unwrap returns the wrapped Error.
+func (c causer) Unwrap() error {
+	return c.err
+}
+
+// StackTrace returns the stack trace of the wrapped Error.
+func (c causer) StackTrace() StackTrace {
+	return c.err.StackTrace()
+}
+
+// Format implements fmt.Formatter by delegating to the wrapped Error.
+func (c causer) Format(s fmt.State, verb rune) {
+	if f, ok := c.err.(fmt.Formatter); ok {
+		f.Format(s, verb)
+		return
+	}
+	io.WriteString(s, c.err.Error())
+}
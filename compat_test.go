@@ -0,0 +1,199 @@
+package errors
+
+import (
+	goerrors "errors"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// pkgStackTrace mimics the stack trace types of github.com/pkg/errors.
+type pkgFrame uintptr
+type pkgStackTrace []pkgFrame
+
+type pkgStackError struct {
+	stack []uintptr
+}
+
+func (err pkgStackError) Error() string {
+	return "foreign"
+}
+func (err pkgStackError) StackTrace() pkgStackTrace {
+	st := make(pkgStackTrace, len(err.stack))
+	for i, pc := range err.stack {
+		st[i] = pkgFrame(pc)
+	}
+	return st
+}
+
+// callersError mimics errors of libraries providing the program counters of the call stack.
+type callersError struct {
+	pcs []uintptr
+}
+
+func (err callersError) Error() string {
+	return "callers"
+}
+func (err callersError) Callers() []uintptr {
+	return err.pcs
+}
+
+func newCallersError() error {
+	var pcs [32]uintptr
+	n := runtime.Callers(1, pcs[:])
+	return callersError{pcs[:n]}
+}
+
+func newPkgStackError() error {
+	var pcs [32]uintptr
+	n := runtime.Callers(1, pcs[:])
+	return pkgStackError{pcs[:n]}
+}
+
+// pkgCauseError mimics wrapped errors of github.com/pkg/errors providing a stack trace and a cause.
+type pkgCauseError struct {
+	pkgStackError error
+	cause         error
+}
+
+func (err pkgCauseError) Error() string {
+	return "pkg cause"
+}
+func (err pkgCauseError) StackTrace() pkgStackTrace {
+	return err.pkgStackError.(pkgStackError).StackTrace()
+}
+func (err pkgCauseError) Cause() error {
+	return err.cause
+}
+
+// pkgCause mimics errors.Cause of github.com/pkg/errors.
+func pkgCause(err error) error {
+	type causer interface {
+		Cause() error
+	}
+	for err != nil {
+		cause, ok := err.(causer)
+		if !ok {
+			break
+		}
+		err = cause.Cause()
+	}
+	return err
+}
+
+func TestStackTraceFrames(t *testing.T) {
+	st := New("test").Make().StackTrace()
+	if assert.NotEmpty(t, st) {
+		assert.Equal(t, "TestStackTraceFrames", fmt.Sprintf("%n", st[0]))
+		assert.Equal(t, "compat_test.go", fmt.Sprintf("%s", st[0]))
+		assert.True(t, strings.HasPrefix(fmt.Sprintf("%+s", st[0]), "github.com/sbreitf1/errors.TestStackTraceFrames\n\t"))
+		assert.True(t, strings.HasPrefix(fmt.Sprintf("%v", st), "[compat_test.go:"))
+	}
+}
+
+func TestMakeDepthStackTrace(t *testing.T) {
+	st := makeTestError().StackTrace()
+	if assert.NotEmpty(t, st) {
+		assert.Equal(t, "TestMakeDepthStackTrace", fmt.Sprintf("%n", st[0]))
+	}
+}
+
+func TestFormat(t *testing.T) {
+	err := New("test").Msg("foo %s", "bar").Make()
+	assert.Equal(t, "foo bar", fmt.Sprintf("%v", err))
+	assert.Equal(t, "foo bar", fmt.Sprintf("%s", err))
+	assert.Equal(t, `"foo bar"`, fmt.Sprintf("%q", err))
+	assert.Equal(t, "foo bar", fmt.Sprintf("%+v", err))
+
+	traced := fmt.Sprintf("%+v", New("test").Msg("foo %s", "bar").Trace().Make())
+	assert.True(t, strings.HasPrefix(traced, "foo bar\ngithub.com/sbreitf1/errors.TestFormat\n\t"))
+	assert.Contains(t, traced, "compat_test.go:")
+}
+
+func TestUnwrap(t *testing.T) {
+	cause := New("cause").Make()
+	err := New("test").Make().Cause(cause)
+	assert.Equal(t, cause, err.Unwrap())
+	assert.Nil(t, cause.Unwrap())
+}
+
+func TestUnwrapForeign(t *testing.T) {
+	assert.True(t, goerrors.Is(Wrap(io.EOF), io.EOF))
+	assert.True(t, goerrors.Is(GenericError.Make().Cause(io.EOF), io.EOF))
+	assert.True(t, goerrors.Is(Wrap(io.EOF).Expand("read"), io.EOF))
+	assert.False(t, goerrors.Is(Wrap(io.ErrUnexpectedEOF), io.EOF))
+
+	_, statErr := os.Stat("does/not/exist")
+	var pathErr *os.PathError
+	assert.True(t, goerrors.As(Wrap(statErr).Expand("open %s", "file"), &pathErr))
+	assert.Equal(t, "does/not/exist", pathErr.Path)
+
+	assert.Equal(t, io.EOF, Compat(Wrap(io.EOF)).(causer).Cause())
+}
+
+func TestWrapForeignStackTrace(t *testing.T) {
+	foreign := newPkgStackError()
+	err := Wrap(foreign)
+	assert.Equal(t, "foreign", err.Error())
+	assert.Equal(t, foreign.(pkgStackError).stack[0], uintptr(err.StackTrace()[0]))
+	assert.True(t, strings.HasPrefix(err.GetStackTrace(), "github.com/sbreitf1/errors.newPkgStackError\n\t"))
+	assert.Contains(t, err.GetStackTrace(), "\nwrapped at github.com/sbreitf1/errors.TestWrapForeignStackTrace\n\t")
+}
+
+func TestWrapForeignOrigin(t *testing.T) {
+	origin := newCallersError()
+	// the innermost stack denotes the origin of the error
+	foreign := fmt.Errorf("outer: %w", pkgCauseError{newPkgStackError(), origin})
+	err := Wrap(foreign)
+	assert.Equal(t, origin.(callersError).pcs[0], uintptr(err.StackTrace()[0]))
+	assert.Equal(t, "newCallersError", fmt.Sprintf("%n", err.StackTrace()[0]))
+
+	traced := fmt.Sprintf("%+v", err)
+	assert.True(t, strings.HasPrefix(traced, "outer: pkg cause\ngithub.com/sbreitf1/errors.newCallersError\n\t"))
+	assert.Contains(t, traced, "\nwrapped at github.com/sbreitf1/errors.TestWrapForeignOrigin\n\t")
+}
+
+func TestCauseForeignStackTrace(t *testing.T) {
+	err := New("test").Make().Cause(newCallersError())
+	wrapped := err.Unwrap().(Error)
+	assert.Equal(t, "newCallersError", fmt.Sprintf("%n", wrapped.StackTrace()[0]))
+	assert.Contains(t, wrapped.GetStackTrace(), "\nwrapped at github.com/sbreitf1/errors.TestCauseForeignStackTrace\n\t")
+}
+
+func TestWrapWithoutForeignStackTrace(t *testing.T) {
+	err := Wrap(fmt.Errorf("plain"))
+	assert.Equal(t, "TestWrapWithoutForeignStackTrace", fmt.Sprintf("%n", err.StackTrace()[0]))
+	assert.NotContains(t, fmt.Sprintf("%+v", err), "wrapped at")
+}
+
+func TestCompat(t *testing.T) {
+	assert.Nil(t, Compat(nil))
+
+	root := New("root").Make()
+	err := New("test").Make().Cause(New("middle").Make().Cause(root))
+	compat := Compat(err)
+	assert.Equal(t, err.Error(), compat.Error())
+	assert.Equal(t, err.StackTrace(), compat.(interface{ StackTrace() StackTrace }).StackTrace())
+	assert.Equal(t, root, pkgCause(compat))
+	assert.Equal(t, "root", fmt.Sprintf("%v", pkgCause(compat)))
+}
+
+func TestCompatWrap(t *testing.T) {
+	template := New("not found").API(404, 7)
+	err := template.Make()
+	compat := Compat(err)
+	assert.Equal(t, err, Wrap(compat))
+	assert.True(t, InstanceOf(compat, template))
+	assert.True(t, KindOf(compat, template))
+	assert.True(t, AreEqual(compat, err))
+
+	r := &requestAborter{}
+	ToRequest(r, compat)
+	assert.Equal(t, 404, r.lastHTTPCode)
+	assert.Equal(t, API(404, 7, "not found"), *r.lastError)
+}
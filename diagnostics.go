@@ -0,0 +1,74 @@
+package errors
+
+import (
+	"fmt"
+)
+
+// Mode denotes the environment the application is running in.
+type Mode int
+
+const (
+	// ProductionMode reports diagnostics only to the DiagnosticHandler (default).
+	ProductionMode Mode = iota
+	// DevelopmentMode panics on diagnostics to reveal misuse as early as possible.
+	DevelopmentMode
+	// TestMode panics on diagnostics to reveal misuse as early as possible.
+	TestMode
+)
+
+func (m Mode) String() string {
+	switch m {
+	case ProductionMode:
+		return "production"
+	case DevelopmentMode:
+		return "development"
+	case TestMode:
+		return "test"
+	default:
+		return fmt.Sprintf("Mode(%d)", int(m))
+	}
+}
+
+var (
+	// CurrentMode controls the handling of diagnostics and development features.
+	CurrentMode = ProductionMode
+
+	// DiagnosticHandler is called for every detected misuse of templates and errors. Can be nil to ignore diagnostics.
+	DiagnosticHandler func(d Diagnostic)
+)
+
+// DiagnosticKind denotes the kind of a detected misuse.
+type DiagnosticKind string
+
+const (
+	// DiagnosticInvalidFormat denotes a malformed format string.
+	DiagnosticInvalidFormat DiagnosticKind = "InvalidFormat"
+	// DiagnosticArgCount denotes a mismatch between format verbs and the number of args.
+	DiagnosticArgCount DiagnosticKind = "ArgCount"
+	// DiagnosticArgType denotes an arg that does not match the type of the corresponding format verb.
+	DiagnosticArgType DiagnosticKind = "ArgType"
+	// DiagnosticArgsReapplied denotes a call to Args() for a message that has already been formatted. The new args are ignored.
+	DiagnosticArgsReapplied DiagnosticKind = "ArgsReapplied"
+)
+
+// Diagnostic describes a detected misuse of templates or errors at runtime.
+type Diagnostic struct {
+	Kind    DiagnosticKind
+	Type    ErrorType
+	Format  string
+	Message string
+}
+
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("errors: %s in %q: %s", d.Kind, d.Format, d.Message)
+}
+
+func reportDiagnostic(kind DiagnosticKind, errType ErrorType, format, msg string, args ...interface{}) {
+	d := Diagnostic{kind, errType, format, fmt.Sprintf(msg, args...)}
+	if DiagnosticHandler != nil {
+		DiagnosticHandler(d)
+	}
+	if CurrentMode != ProductionMode {
+		panic(d.String())
+	}
+}
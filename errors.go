@@ -1,7 +1,9 @@
 package errors
 
 import (
+	"context"
 	"fmt"
+	"time"
 )
 
 const (
@@ -36,6 +38,22 @@ type Error interface {
 
 	GetID() string
 	GetStackTrace() string
+	// StackTrace returns the call stack captured on instantiation or by the wrapped error. It is compatible with github.com/pkg/errors.
+	StackTrace() StackTrace
+	// Unwrap returns the cause of the error or nil.
+	Unwrap() error
+	// GetField returns the value of a named placeholder or false, if no value is set.
+	GetField(name string) (interface{}, bool)
+	// GetFields returns a copy of all named placeholder values.
+	GetFields() map[string]interface{}
+	// Fingerprint returns a stable hash to group occurrences of the same problem. It is derived from the error type, the types of all causes and the top in-project stack frames.
+	Fingerprint() string
+	// CreatedAt returns the time of instantiation.
+	CreatedAt() time.Time
+	// GetMetadata returns host, process and build info or false, if CaptureMetadata was disabled on instantiation.
+	GetMetadata() (Metadata, bool)
+	// GetGoroutine returns the execution context of the error or false, if neither CaptureGoroutine was enabled on instantiation nor labels have been supplied.
+	GetGoroutine() (Goroutine, bool)
 
 	// Untrack disables id and stack trace printing for this error.
 	Untrack() Error
@@ -45,13 +63,17 @@ type Error interface {
 	Msg(msg string, args ...interface{}) Error
 	// Args returns a new Error object with filled placeholders. A safe message remains safe.
 	Args(args ...interface{}) Error
+	// With returns a new Error object with a value for the named placeholder {name}. The value is also printed to log as structured field. A safe message remains safe.
+	With(name string, value interface{}) Error
+	// Labels returns a new Error object with the pprof labels of ctx as set by pprof.WithLabels or pprof.Do.
+	Labels(ctx context.Context) Error
 	// Cause adds the given error as cause. It's error message will be appended to the output.
 	Cause(err error) Error
 	// StrCause adds a detailed error message as cause.
 	StrCause(str string, args ...interface{}) Error
-	// Expand creates a copy of this error with given message and sets the current error as cause.
+	// Expand creates a copy of this error with given message and sets the current error as cause. The message is always formatted with the given args, so percent signs need to be escaped even without args.
 	Expand(msg string, args ...interface{}) Error
-	// ExpandSafe creates a copy of this error with given message and sets the current error as cause. The expanded message is marked as safe.
+	// ExpandSafe creates a copy of this error with given message and sets the current error as cause. The expanded message is marked as safe and formatted like Expand.
 	ExpandSafe(msg string, args ...interface{}) Error
 
 	// Tag adds a named tag to the error.
@@ -71,30 +93,41 @@ type Error interface {
 	Equals(other error) bool
 	// Is returns trhe when the error is an instance of the given template.
 	Is(template Template) bool
+	// IsKindOf returns true when the error is an instance of the given template or any template derived from it.
+	IsKindOf(parent TypedError) bool
+	// GetParentTypes returns the types of all ancestors of the error template starting with the direct parent.
+	GetParentTypes() []ErrorType
 
 	// HTTPCode sets the http response code.
 	HTTPCode(code int) Error
+	// Severity sets the severity used for logging.
+	Severity(severity Severity) Error
+	// GetSeverity returns the severity of the error. Defaults to SeverityWarn for HTTP codes 4xx and SeverityError otherwise.
+	GetSeverity() Severity
 	// ErrCode sets the api error code.
 	ErrCode(code int) Error
 	// Safe marks the error as safe for printing to end-user.
 	Safe() Error
 	// API returns the corresponding APIError object.
 	API() APIError
-	// ToRequest writes the APIError message representation to a HTTP request and aborts pipeline execution.
+	// LocalizedAPI returns the corresponding APIError object with the safe message translated to the first matching locale in Messages.
+	LocalizedAPI(locales ...string) APIError
+	// ToRequest writes the APIError message representation to a HTTP request and aborts pipeline execution. The message is localized according to the Accept-Language header if r is a HeaderGetter.
 	ToRequest(r RequestAborter)
 	// ToRequestAndLog calls ToRequest(r) and ToLog(...except).
 	ToRequestAndLog(r RequestAborter, except ...TypedError)
 	// ToRequestAndLog calls ToRequest(r) and ForceLog(...except).
 	ToRequestAndForceLog(r RequestAborter, except ...TypedError)
 
-	// ToLog writes the error message with debug data to the log.
+	// ToLog writes the error message with debug data to the log. Untracked errors and errors skipped by Policy are not written.
 	ToLog(except ...TypedError)
-	// ForceLog writes the error message (and also untracked ones) with debug data to the log.
+	// ForceLog writes the error message (and also untracked ones) with debug data to the log. Policy is ignored.
 	ForceLog(except ...TypedError)
 }
 
 type baseError struct {
 	errType ErrorType
+	parents []ErrorType
 	content content
 	flags   flags
 	trace   trace
@@ -110,6 +143,17 @@ func (err baseError) GetID() string {
 func (err baseError) GetStackTrace() string {
 	return err.trace.stackTrace
 }
+func (err baseError) GetField(name string) (interface{}, bool) {
+	val, ok := err.content.fields[name]
+	return val, ok
+}
+func (err baseError) GetFields() map[string]interface{} {
+	fields := make(map[string]interface{}, len(err.content.fields))
+	for name, val := range err.content.fields {
+		fields[name] = val
+	}
+	return fields
+}
 
 func (err baseError) IsTagged(tag string) bool {
 	_, ok := err.flags.tags[tag]
@@ -137,91 +181,88 @@ func (err baseError) GetTagInt(tag string) (int, bool) {
 func (err baseError) Untrack() Error {
 	flags := err.flags
 	flags.track = false
-	return baseError{err.errType, err.content, flags, err.trace, err.api}
+	return baseError{err.errType, err.parents, err.content, flags, err.trace, err.api}
 }
 func (err baseError) NoTrace() Error {
 	flags := err.flags
 	flags.trace = false
-	return baseError{err.errType, err.content, flags, err.trace, err.api}
+	return baseError{err.errType, err.parents, err.content, flags, err.trace, err.api}
 }
 func (err baseError) Safe() Error {
 	flags := err.flags
 	flags.isSafe = true
-	return baseError{err.errType, err.content, flags, err.trace, err.api}
+	return baseError{err.errType, err.parents, err.content, flags, err.trace, err.api}
 }
 func (err baseError) Msg(msg string, args ...interface{}) Error {
-	content := err.content
-	if len(args) == 0 {
-		content.message = msg
-	} else {
-		// hack: go-vet erroneously detects missing args when calling Sprintf directly
-		// -> using the encapsulation prevents go-vet from processing the format string
-		content.message = fmt.Sprintf(fmt.Sprintf("%s", msg), args...)
-	}
+	content := err.content.withMessage(err.errType, msg, args)
+	content.templated = false
 	flags := err.flags
 	flags.isSafe = false
-	return baseError{err.errType, content, flags, err.trace, err.api}
+	return baseError{err.errType, err.parents, content, flags, err.trace, err.api}
 }
 func (err baseError) Args(args ...interface{}) Error {
-	content := err.content
-	content.message = fmt.Sprintf(content.message, args...)
-	return baseError{err.errType, content, err.flags, err.trace, err.api}
+	content := err.content.withArgs(err.errType, args)
+	return baseError{err.errType, err.parents, content, err.flags, err.trace, err.api}
+}
+func (err baseError) With(name string, value interface{}) Error {
+	return baseError{err.errType, err.parents, err.content.withField(name, value), err.flags, err.trace, err.api}
 }
 func (err baseError) Cause(cause error) Error {
 	content := err.content
-	content.cause = Wrap(cause)
-	return baseError{err.errType, content, err.flags, err.trace, err.api}
+	content.cause = wrap(cause, false, 1)
+	return baseError{err.errType, err.parents, content, err.flags, err.trace, err.api}
 }
 func (err baseError) StrCause(str string, args ...interface{}) Error {
 	content := err.content
 	content.cause = GenericError.Msg(str, args...).Untrack().Make()
-	return baseError{err.errType, content, err.flags, err.trace, err.api}
+	return baseError{err.errType, err.parents, content, err.flags, err.trace, err.api}
 }
 func (err baseError) Expand(msg string, args ...interface{}) Error {
-	content := err.content
-	content.message = fmt.Sprintf(msg, args...)
+	content := err.content.withMessage(err.errType, msg, nil).withArgs(err.errType, args)
+	content.templated = false
 	content.cause = err
 	flags := err.flags
 	flags.isSafe = false
-	return baseError{err.errType, content, flags, err.trace, err.api}
+	return baseError{err.errType, err.parents, content, flags, err.trace, err.api}
 }
 func (err baseError) ExpandSafe(msg string, args ...interface{}) Error {
-	content := err.content
-	content.message = fmt.Sprintf(msg, args...)
+	content := err.content.withMessage(err.errType, msg, nil).withArgs(err.errType, args)
+	content.templated = false
 	content.cause = err
 	flags := err.flags
 	flags.isSafe = true
-	return baseError{err.errType, content, flags, err.trace, err.api}
+	return baseError{err.errType, err.parents, content, flags, err.trace, err.api}
 }
 
 func (err baseError) Tag(tag string) Error {
 	flags := err.flags
 	flags.tags[tag] = nil
-	return baseError{err.errType, err.content, flags, err.trace, err.api}
+	return baseError{err.errType, err.parents, err.content, flags, err.trace, err.api}
 }
 
 func (err baseError) TagStr(tag, value string) Error {
 	flags := err.flags
 	flags.tags[tag] = value
-	return baseError{err.errType, err.content, flags, err.trace, err.api}
+	return baseError{err.errType, err.parents, err.content, flags, err.trace, err.api}
 }
 
 func (err baseError) TagInt(tag string, value int) Error {
 	flags := err.flags
 	flags.tags[tag] = value
-	return baseError{err.errType, err.content, flags, err.trace, err.api}
+	return baseError{err.errType, err.parents, err.content, flags, err.trace, err.api}
 }
 
 func (err baseError) HTTPCode(code int) Error {
 	api := err.api
 	api.httpCode = code
-	return baseError{err.errType, err.content, err.flags, err.trace, api}
+	return baseError{err.errType, err.parents, err.content, err.flags, err.trace, api}
 }
 
 func (err baseError) ErrCode(code int) Error {
 	api := err.api
 	api.errCode = code
-	return baseError{err.errType, err.content, err.flags, err.trace, api}
+	api.codeOwner = err.errType
+	return baseError{err.errType, err.parents, err.content, err.flags, err.trace, api}
 }
 
 /* ############################################# */
@@ -239,6 +280,12 @@ func (err baseError) Equals(other error) bool {
 func (err baseError) Is(template Template) bool {
 	return err.errType == template.GetType()
 }
+func (err baseError) IsKindOf(parent TypedError) bool {
+	return isKindOf(err.errType, err.parents, parent.GetType())
+}
+func (err baseError) GetParentTypes() []ErrorType {
+	return append([]ErrorType(nil), err.parents...)
+}
 
 // AreEqual returns true if the type of both errors is the same regardless of the specific error message. Also returns true if both errors are nil.
 func AreEqual(err1, err2 error) bool {
@@ -266,11 +313,38 @@ func InstanceOf(err error, template Template) bool {
 	return getErrorType(err) == template.GetType()
 }
 
+// KindOf returns true if the given error is an instance of the given template or any template derived from it. A nil error always returns false.
+func KindOf(err error, parent TypedError) bool {
+	if err == nil {
+		return false
+	}
+
+	switch e := err.(type) {
+	case Error:
+		return e.IsKindOf(parent)
+	case causer:
+		return e.err.IsKindOf(parent)
+	}
+	return getErrorType(err) == parent.GetType()
+}
+
+func isKindOf(errType ErrorType, parents []ErrorType, parentType ErrorType) bool {
+	if errType == parentType {
+		return true
+	}
+	for _, p := range parents {
+		if p == parentType {
+			return true
+		}
+	}
+	return false
+}
+
 /* ############################################# */
 /* ###             Instantiation             ### */
 /* ############################################# */
 
-// Wrap encapsulates any go-error in the extended Error type. Returns nil if baseErr is nil.
+// Wrap encapsulates any go-error in the extended Error type. Returns nil if baseErr is nil. The original stack trace of errors providing StackTrace() like github.com/pkg/errors or Callers() is kept and the call to Wrap is recorded as additional frame.
 func Wrap(baseErr error) Error {
 	return wrap(baseErr, false, 1)
 }
@@ -290,6 +364,9 @@ func wrap(baseErr error, withType bool, depth int) Error {
 	case Error:
 		// do not further wrap Error interface
 		return e
+	case causer:
+		// return the Error converted by Compat
+		return e.err
 	default:
 		errType := getErrorType(baseErr)
 
@@ -302,7 +379,17 @@ func wrap(baseErr error, withType bool, depth int) Error {
 			}
 		}
 
-		return New(string(errType)).Msg(msg).Trace().make(depth + 1)
+		err := New(string(errType)).Msg(msg).Trace().make(depth + 1).(baseError)
+		if pcs, ok := foreignStack(baseErr); ok {
+			// keep the origin of the error and only record where it has been wrapped
+			if len(err.trace.stack) > 0 {
+				err.trace.wrapSite = err.trace.stack[0]
+			}
+			err.trace.stack = pcs
+			err.trace.stackTrace = formatStack(pcs, err.trace.wrapSite)
+		}
+		err.content.foreign = baseErr
+		return err
 	}
 }
 
@@ -310,6 +397,8 @@ func getErrorType(err error) ErrorType {
 	switch e := err.(type) {
 	case Error:
 		return e.GetType()
+	case causer:
+		return e.err.GetType()
 	default:
 		return ErrorType(fmt.Sprintf("%T", err))
 	}
@@ -329,17 +418,29 @@ func (err baseError) SafeString() string {
 	return err.string(true)
 }
 func (err baseError) string(onlySafe bool) string {
+	return err.localizedString(onlySafe, nil)
+}
+func (err baseError) localizedString(onlySafe bool, locales []string) string {
 	if !onlySafe || err.flags.isSafe {
 		var prefix string
 		if err.content.message == "" {
 			prefix = string(err.errType)
 		} else {
-			prefix = err.content.message
+			prefix = err.content.text()
+			if err.content.templated && len(locales) > 0 {
+				if msg, ok := Messages.lookup(locales, err.errType, err.api.errCode); ok {
+					localized := err.content
+					localized.message = msg
+					prefix = localized.text()
+				}
+			}
 		}
 
 		suffix := ""
 		if err.content.cause != nil {
-			if onlySafe {
+			if cause, ok := err.content.cause.(baseError); ok {
+				suffix = cause.localizedString(onlySafe, locales)
+			} else if onlySafe {
 				suffix = err.content.cause.SafeString()
 			} else {
 				suffix = err.content.cause.String()
@@ -365,12 +466,21 @@ func (err baseError) ToRequestAndForceLog(r RequestAborter, except ...TypedError
 }
 
 func (err baseError) ToRequest(r RequestAborter) {
+	if h, ok := r.(HeaderGetter); ok {
+		err.LocalizedAPI(ParseAcceptLanguage(h.GetHeader("Accept-Language"))...).ToRequest(r)
+		return
+	}
 	err.API().ToRequest(r)
 }
 
 func (err baseError) ToLog(except ...TypedError) {
-	if err.flags.track {
+	switch Policy.Decide(err) {
+	case Log:
 		err.toLog(except...)
+	case Abstain:
+		if err.flags.track {
+			err.toLog(except...)
+		}
 	}
 }
 
@@ -380,23 +490,22 @@ func (err baseError) ForceLog(except ...TypedError) {
 
 func (err baseError) toLog(except ...TypedError) {
 	for _, exceptErr := range except {
-		if areEqual(err.errType, exceptErr.GetType()) {
+		if isKindOf(err.errType, err.parents, exceptErr.GetType()) {
 			// do not print error as it is explicitly excluded
 			return
 		}
 	}
-	if len(err.trace.id) > 0 {
-		if !err.flags.track {
-			Logger("[ERR] %v", err.Error())
-		} else {
-			Logger("[ERR %v] %v", err.trace.id, err.Error())
-		}
+	severity := err.GetSeverity()
+	if severity < MinSeverity {
+		return
 	}
-	if err.flags.trace && len(err.trace.stackTrace) > 0 {
-		if !err.flags.track {
-			Logger("[STACK] %v", err.trace.stackTrace)
-		} else {
-			Logger("[STACK %v] %v", err.trace.id, err.trace.stackTrace)
-		}
+	if Limiter != nil && !Limiter.Allow(err) {
+		return
+	}
+	record := Record{err, severity, time.Now()}
+	if LogSink != nil {
+		LogSink.Write(record)
+		return
 	}
+	writeLogLines(record, FormatOptions{Stack: true}, logf)
 }
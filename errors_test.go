@@ -154,6 +154,67 @@ func TestEquals(t *testing.T) {
 	assert.True(t, AreEqual(nil, nil))
 }
 
+func TestTypedTemplate(t *testing.T) {
+	tmpl := NewTyped("storage.NotFound", "File %s not found")
+	err := tmpl.Make().Args("foo.txt")
+	assert.Equal(t, ErrorType("storage.NotFound"), err.GetType())
+	assert.Equal(t, "File foo.txt not found", err.Error())
+	assert.True(t, err.Is(tmpl))
+	assert.True(t, InstanceOf(err, NewTyped("storage.NotFound", "Reworded message")))
+	assert.False(t, InstanceOf(err, New("File %s not found")))
+	assert.False(t, InstanceOf(err, NewTyped("network.NotFound", "File %s not found")))
+}
+
+func TestQualifiedTemplate(t *testing.T) {
+	tmpl := New("not found").Qualify()
+	assert.Equal(t, ErrorType("github.com/sbreitf1/errors.not found"), tmpl.GetType())
+	assert.Equal(t, "not found", tmpl.Make().Error())
+	assert.False(t, InstanceOf(tmpl.Make(), New("not found")))
+
+	assert.Equal(t, "github.com/foo/bar", funcPackage("github.com/foo/bar.(*T).Method"))
+	assert.Equal(t, "github.com/foo/bar.v2/baz", funcPackage("github.com/foo/bar.v2/baz.init.0"))
+	assert.Equal(t, "main", funcPackage("main.main"))
+}
+
+func TestDerive(t *testing.T) {
+	notFound := NewTyped("storage.NotFound", "Resource %s not found").API(404, 10).Tag("storage")
+	userNotFound := notFound.Derive("storage.UserNotFound").ErrCode(11)
+	adminNotFound := userNotFound.Derive("storage.AdminNotFound").Tag("admin")
+
+	err := adminNotFound.Make().Args("root")
+	assert.Equal(t, "Resource root not found", err.Error())
+	assert.Equal(t, []ErrorType{"storage.UserNotFound", "storage.NotFound"}, err.GetParentTypes())
+	assert.True(t, err.IsKindOf(notFound))
+	assert.True(t, err.IsKindOf(userNotFound))
+	assert.True(t, err.IsKindOf(adminNotFound))
+	assert.False(t, err.Is(notFound))
+	assert.False(t, notFound.Make().IsKindOf(userNotFound))
+	assert.True(t, userNotFound.IsKindOf(notFound))
+	assert.True(t, KindOf(err, notFound))
+	assert.False(t, KindOf(nil, notFound))
+	assert.False(t, KindOf(fmt.Errorf("foo"), notFound))
+
+	api := err.API()
+	assert.Equal(t, API(404, 11, "Resource root not found"), api)
+	assert.True(t, err.IsTagged("storage"))
+	assert.True(t, err.IsTagged("admin"))
+	assert.False(t, notFound.Make().IsTagged("admin"))
+}
+
+func TestToLogExceptParent(t *testing.T) {
+	notFound := NewTyped("storage.NotFound", "Resource not found")
+	err := notFound.Derive("storage.UserNotFound").Make()
+	lb := setLogBuffer()
+	err.ToLog(notFound)
+	assert.Equal(t, "", lb.String())
+	err.ToLog(New("other"))
+	assert.True(t, strings.Contains(lb.String(), "Resource not found"))
+
+	lb = setLogBuffer()
+	notFound.Make().ToLog(err)
+	assert.True(t, strings.Contains(lb.String(), "Resource not found"))
+}
+
 func TestWrap(t *testing.T) {
 	err := Wrap(fmt.Errorf("inner error"))
 	assert.True(t, strings.Contains(err.Error(), "inner error"))
@@ -337,6 +398,16 @@ func TestForceLogUntrackedStackTrace(t *testing.T) {
 	assert.True(t, strings.Contains(str, "TestForceLogUntrackedStackTrace"), "Log should contain stack trace")
 }
 
+func TestMakeDepth(t *testing.T) {
+	err := makeTestError()
+	assert.False(t, strings.Contains(err.GetStackTrace(), "makeTestError"), "Stack trace should not contain helper function")
+	assert.True(t, strings.Contains(err.GetStackTrace(), "TestMakeDepth"), "Stack trace should contain caller")
+}
+
+func makeTestError() Error {
+	return New("TestError").Trace().MakeDepth(1)
+}
+
 /* ############################################# */
 /* ###                Helper                 ### */
 /* ############################################# */
@@ -372,3 +443,10 @@ func setLogBuffer() *logBuffer {
 	Logger = lb.Write
 	return lb
 }
+
+func TestExpandFormatsWithoutArgs(t *testing.T) {
+	err := New("base").Make()
+	assert.Equal(t, "50% done: base", err.Expand("50%% done").Error())
+	assert.Equal(t, "50% done", err.ExpandSafe("50%% done").SafeString())
+	assert.Equal(t, "50% of 3 done: base", err.Expand("50%% of %d done", 3).Error())
+}
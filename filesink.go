@@ -0,0 +1,235 @@
+package errors
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// backupTimeFormat is used in the names of rotated files and sorts chronologically.
+const backupTimeFormat = "20060102T150405.000000000"
+
+// FileSinkOptions configure the rotation of a FileSink.
+type FileSinkOptions struct {
+	// MaxSize is the size in bytes after which the file is rotated. Zero disables rotation by size.
+	MaxSize int64
+	// MaxAge is the duration after which the file is rotated. Zero disables rotation by age.
+	MaxAge time.Duration
+	// Keep is the number of rotated files to keep. All rotated files are kept if zero.
+	Keep int
+	// Compress enables gzip compression of rotated files.
+	Compress bool
+	// Format encodes records. Defaults to JSON Lines with stack traces.
+	Format Formatter
+}
+
+// FileSink writes one JSON object per error to a file and rotates it by size and age. Rotated files are named like "errors.log.20240502T101504.000000000" with optional ".gz" suffix. It is safe for concurrent use.
+type FileSink struct {
+	path    string
+	options FileSinkOptions
+	// encode formats records and reports values that could not be encoded.
+	encode func(record Record) ([]byte, error)
+	now    func() time.Time
+
+	mutex  sync.Mutex
+	file   *os.File
+	size   int64
+	opened time.Time
+	closed bool
+	// lastErr is the last error of an encoding, write or rotation.
+	lastErr error
+}
+
+// NewFileSink opens or creates the file at path and appends records to it.
+func NewFileSink(path string, options FileSinkOptions) (*FileSink, error) {
+	encode := func(record Record) ([]byte, error) {
+		return options.Format(record), nil
+	}
+	if options.Format == nil {
+		format := FormatOptions{Stack: true}
+		options.Format = JSONFormatter(format)
+		encode = func(record Record) ([]byte, error) {
+			return encodeJSON(record, format)
+		}
+	}
+	s := &FileSink{path: path, options: options, encode: encode, now: time.Now}
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// WithClock replaces the time source used for rotation by age and the names of rotated files and returns the sink. Used for tests.
+func (s *FileSink) WithClock(now func() time.Time) *FileSink {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.now = now
+	s.opened = now()
+	return s
+}
+
+func (s *FileSink) open() error {
+	file, err := os.OpenFile(s.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	s.file, s.size, s.opened = file, info.Size(), s.now()
+	return nil
+}
+
+// Write appends the formatted record to the file and rotates the file before, if required. Errors, including values the default format could not encode, are available via LastError().
+func (s *FileSink) Write(record Record) {
+	data, encodeErr := s.encode(record)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.closed {
+		return
+	}
+	if encodeErr != nil {
+		s.lastErr = encodeErr
+	}
+	if s.size > 0 && ((s.options.MaxSize > 0 && s.size+int64(len(data)) > s.options.MaxSize) || (s.options.MaxAge > 0 && s.now().Sub(s.opened) >= s.options.MaxAge)) {
+		if err := s.rotate(); err != nil {
+			s.lastErr = err
+			if s.file == nil {
+				return
+			}
+		}
+	}
+	n, err := s.file.Write(data)
+	s.size += int64(n)
+	if err != nil {
+		s.lastErr = err
+	}
+}
+
+// Rotate closes the current file, renames it and opens a new file.
+func (s *FileSink) Rotate() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.closed {
+		return os.ErrClosed
+	}
+	return s.rotate()
+}
+
+func (s *FileSink) rotate() error {
+	if s.file != nil {
+		if err := s.file.Close(); err != nil {
+			return err
+		}
+		s.file = nil
+	}
+
+	backup := s.path + "." + s.now().UTC().Format(backupTimeFormat)
+	if err := os.Rename(s.path, backup); err != nil && !os.IsNotExist(err) {
+		if openErr := s.open(); openErr != nil {
+			s.lastErr = openErr
+		}
+		return err
+	}
+	if err := s.open(); err != nil {
+		return err
+	}
+	if s.options.Compress {
+		if err := compressFile(backup); err != nil {
+			return err
+		}
+	}
+	return s.removeBackups()
+}
+
+// compressFile replaces the file by a gzip compressed file with suffix ".gz".
+func compressFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	dst, err := os.OpenFile(path+".gz", os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	gz := gzip.NewWriter(dst)
+	if _, err := io.Copy(gz, src); err != nil {
+		dst.Close()
+		os.Remove(path + ".gz")
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		dst.Close()
+		os.Remove(path + ".gz")
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		return err
+	}
+	src.Close()
+	return os.Remove(path)
+}
+
+// Backups returns the paths of all rotated files starting with the oldest one.
+func (s *FileSink) Backups() ([]string, error) {
+	matches, err := filepath.Glob(s.path + ".*")
+	if err != nil {
+		return nil, err
+	}
+	var backups []string
+	for _, match := range matches {
+		suffix := strings.TrimSuffix(strings.TrimPrefix(match, s.path+"."), ".gz")
+		if _, err := time.Parse(backupTimeFormat, suffix); err == nil {
+			backups = append(backups, match)
+		}
+	}
+	sort.Strings(backups)
+	return backups, nil
+}
+
+func (s *FileSink) removeBackups() error {
+	if s.options.Keep <= 0 {
+		return nil
+	}
+	backups, err := s.Backups()
+	if err != nil {
+		return err
+	}
+	for len(backups) > s.options.Keep {
+		if err := os.Remove(backups[0]); err != nil {
+			return err
+		}
+		backups = backups[1:]
+	}
+	return nil
+}
+
+// LastError returns the last error of an encoding, write or rotation or nil.
+func (s *FileSink) LastError() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.lastErr
+}
+
+// Close closes the file. Subsequent writes are ignored.
+func (s *FileSink) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	if s.file == nil {
+		return nil
+	}
+	return s.file.Close()
+}
@@ -0,0 +1,216 @@
+package errors
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func readJSONLines(t *testing.T, path string) []map[string]interface{} {
+	file, err := os.Open(path)
+	if !assert.NoError(t, err) {
+		return nil
+	}
+	defer file.Close()
+
+	var r io.Reader = file
+	if strings.HasSuffix(path, ".gz") {
+		gz, err := gzip.NewReader(file)
+		if !assert.NoError(t, err) {
+			return nil
+		}
+		r = gz
+	}
+
+	var objects []map[string]interface{}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		var obj map[string]interface{}
+		assert.NoError(t, json.Unmarshal(scanner.Bytes(), &obj))
+		objects = append(objects, obj)
+	}
+	return objects
+}
+
+func TestFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "errors.log")
+	sink, err := NewFileSink(path, FileSinkOptions{})
+	if !assert.NoError(t, err) {
+		return
+	}
+	LogSink = sink
+	defer func() { LogSink = nil }()
+
+	cause := New("Connection refused").Trace().Make()
+	logged := New("Query %q failed").Trace().Make().Args("SELECT 1").With("db", "users").Tag("sql").HTTPCode(503).ErrCode(42).Cause(cause)
+	logged.ToLog()
+	assert.NoError(t, sink.Close())
+	assert.NoError(t, sink.LastError())
+
+	objects := readJSONLines(t, path)
+	if assert.Len(t, objects, 1) {
+		obj := objects[0]
+		assert.Equal(t, logged.GetID(), obj["id"])
+		assert.Equal(t, "Query %q failed", obj["type"])
+		assert.Equal(t, `Query "SELECT 1" failed: Connection refused`, obj["message"])
+		assert.Equal(t, GenericSafeErrorMessage, obj["safeMessage"])
+		assert.Equal(t, float64(503), obj["httpCode"])
+		assert.Equal(t, float64(42), obj["errCode"])
+		assert.Equal(t, "error", obj["severity"])
+		assert.Equal(t, map[string]interface{}{"sql": nil}, obj["tags"])
+		assert.Equal(t, map[string]interface{}{"db": "users"}, obj["fields"])
+		assert.Equal(t, []interface{}{map[string]interface{}{"type": "Connection refused", "message": "Connection refused"}}, obj["causes"])
+		assert.Len(t, obj["stack"], 2)
+		assert.NotEmpty(t, obj["time"])
+		assert.NotEmpty(t, obj["createdAt"])
+	}
+
+	sink.Write(testRecord(0))
+	assert.Len(t, readJSONLines(t, path), 1, "Writes after close should be ignored")
+}
+
+func TestFileSinkUnsupportedValues(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "errors.log")
+	sink, err := NewFileSink(path, FileSinkOptions{})
+	if !assert.NoError(t, err) {
+		return
+	}
+	sink.Write(Record{New("Computation failed").Make().With("ratio", math.NaN()).With("callback", func() {}).With("count", 3), SeverityError, time.Now()})
+	assert.Error(t, sink.LastError(), "Unsupported values should be reported")
+	assert.NoError(t, sink.Close())
+
+	objects := readJSONLines(t, path)
+	if assert.Len(t, objects, 1, "The record should not be lost") {
+		obj := objects[0]
+		assert.Equal(t, "Computation failed", obj["message"])
+		fields, _ := obj["fields"].(map[string]interface{})
+		assert.Equal(t, "NaN", fields["ratio"])
+		assert.IsType(t, "", fields["callback"])
+		assert.Equal(t, float64(3), fields["count"])
+	}
+}
+
+func TestFileSinkRotateBySize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "errors.log")
+	clock := &testClock{now: time.Date(2024, 5, 2, 10, 0, 0, 0, time.UTC)}
+	sink, err := NewFileSink(path, FileSinkOptions{MaxSize: 300, Keep: 2, Format: JSONFormatter(FormatOptions{})})
+	if !assert.NoError(t, err) {
+		return
+	}
+	sink.WithClock(clock.Now)
+	defer sink.Close()
+
+	for i := 0; i < 10; i++ {
+		sink.Write(testRecord(i))
+		clock.Advance(time.Second)
+	}
+	assert.NoError(t, sink.LastError())
+
+	backups, err := sink.Backups()
+	assert.NoError(t, err)
+	assert.Len(t, backups, 2, "Only the configured number of rotated files should be kept")
+
+	info, err := os.Stat(path)
+	assert.NoError(t, err)
+	assert.True(t, info.Size() <= 300)
+	for _, backup := range backups {
+		info, err := os.Stat(backup)
+		assert.NoError(t, err)
+		assert.True(t, info.Size() <= 300)
+	}
+
+	// the latest records are kept in order
+	var messages []string
+	for _, file := range append(backups, path) {
+		for _, obj := range readJSONLines(t, file) {
+			messages = append(messages, obj["message"].(string))
+		}
+	}
+	assert.Equal(t, "record 9", messages[len(messages)-1])
+	for i := 1; i < len(messages); i++ {
+		assert.True(t, messages[i-1] < messages[i])
+	}
+}
+
+func TestFileSinkRotateByAge(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "errors.log")
+	clock := &testClock{now: time.Date(2024, 5, 2, 10, 0, 0, 0, time.UTC)}
+	sink, err := NewFileSink(path, FileSinkOptions{MaxAge: time.Hour, Compress: true})
+	if !assert.NoError(t, err) {
+		return
+	}
+	sink.WithClock(clock.Now)
+	defer sink.Close()
+
+	sink.Write(testRecord(0))
+	clock.Advance(30 * time.Minute)
+	sink.Write(testRecord(1))
+	backups, _ := sink.Backups()
+	assert.Len(t, backups, 0)
+
+	clock.Advance(30 * time.Minute)
+	sink.Write(testRecord(2))
+	assert.NoError(t, sink.LastError())
+	backups, _ = sink.Backups()
+	if assert.Len(t, backups, 1) {
+		assert.Equal(t, path+".20240502T110000.000000000.gz", backups[0])
+		assert.Len(t, readJSONLines(t, backups[0]), 2)
+	}
+	assert.Len(t, readJSONLines(t, path), 1)
+}
+
+func TestFileSinkConcurrent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "errors.log")
+	sink, err := NewFileSink(path, FileSinkOptions{MaxSize: 4096, Format: JSONFormatter(FormatOptions{})})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var wg sync.WaitGroup
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				sink.Write(testRecord(w*50 + i))
+			}
+		}(w)
+	}
+	wg.Wait()
+	assert.NoError(t, sink.Close())
+	assert.NoError(t, sink.LastError())
+
+	backups, _ := sink.Backups()
+	count := 0
+	for _, file := range append(backups, path) {
+		count += len(readJSONLines(t, file))
+	}
+	assert.Equal(t, 400, count)
+}
+
+func TestFileSinkAppend(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "errors.log")
+	for i := 0; i < 2; i++ {
+		sink, err := NewFileSink(path, FileSinkOptions{})
+		if !assert.NoError(t, err) {
+			return
+		}
+		sink.Write(testRecord(i))
+		assert.NoError(t, sink.Close())
+	}
+	assert.Len(t, readJSONLines(t, path), 2)
+
+	_, err := NewFileSink(filepath.Join(path, "invalid"), FileSinkOptions{})
+	assert.Error(t, err)
+}
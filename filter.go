@@ -0,0 +1,137 @@
+package errors
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// FrameFilter controls which frames of stack traces are printed and how source files are displayed. It applies to log output, %+v formatting and JSON encoding of errors.
+type FrameFilter struct {
+	// TrimPaths replaces the directory of source files by the import path of the package, which removes GOPATH, module cache, module root and GOROOT prefixes.
+	TrimPaths bool
+	// TrimPrefixes are removed from the paths of source files.
+	TrimPrefixes []string
+	// Hide contains package patterns of frames to remove. A pattern matches the package path exactly or all sub packages if it ends with "/..." like "github.com/gin-gonic/gin/...".
+	Hide []string
+	// Collapse contains package patterns of framework frames. Consecutive frames matching the same pattern are printed as a single line.
+	Collapse []string
+	// MaxDepth limits the number of printed lines per level of a cause chain. Zero disables the limit.
+	MaxDepth int
+}
+
+var (
+	// StackFilter is applied to all printed stack traces. Stack traces are printed unfiltered by default.
+	StackFilter = FrameFilter{}
+
+	// RecommendedFrameFilter trims paths and hides runtime and test runner frames.
+	RecommendedFrameFilter = FrameFilter{
+		TrimPaths: true,
+		Hide:      []string{"runtime", "testing"},
+		MaxDepth:  32,
+	}
+)
+
+// frameLine is a printed line of a stack trace. It either denotes a single frame or a number of skipped frames.
+type frameLine struct {
+	function string
+	file     string
+	line     int
+	// skipped is the number of collapsed or omitted frames represented by this line.
+	skipped int
+	// note describes skipped frames.
+	note string
+	// source is the untrimmed path of the source file.
+	source string
+}
+
+// text returns the line in the format of %+v of github.com/pkg/errors.
+func (l frameLine) text() string {
+	if l.skipped > 0 {
+		return "\t" + l.String()
+	}
+	return fmt.Sprintf("%s\n\t%s:%d", l.function, l.file, l.line)
+}
+
+func (l frameLine) String() string {
+	if l.skipped > 0 {
+		if l.note != "" {
+			return fmt.Sprintf("... %d frames of %s", l.skipped, l.note)
+		}
+		return fmt.Sprintf("... %d more", l.skipped)
+	}
+	return fmt.Sprintf("%s %s:%d", l.function, l.file, l.line)
+}
+
+// apply returns the printed lines for the given program counters. The number of additional frames that are not part of pcs, like frames shared with a cause, is appended as "... N more" line.
+func (f FrameFilter) apply(pcs []uintptr, more int) []frameLine {
+	var lines []frameLine
+	for _, pc := range pcs {
+		l := f.line(pc)
+		pkg := funcPackage(l.function)
+		if matchPackage(f.Hide, pkg) {
+			continue
+		}
+		if pattern, ok := matchingPattern(f.Collapse, pkg); ok {
+			if last := len(lines) - 1; last >= 0 && lines[last].skipped > 0 && lines[last].note == pattern {
+				lines[last].skipped++
+			} else {
+				lines = append(lines, frameLine{skipped: 1, note: pattern})
+			}
+			continue
+		}
+		lines = append(lines, l)
+	}
+
+	if f.MaxDepth > 0 && len(lines) > f.MaxDepth {
+		for _, l := range lines[f.MaxDepth:] {
+			if l.skipped > 0 {
+				more += l.skipped
+			} else {
+				more++
+			}
+		}
+		lines = lines[:f.MaxDepth]
+	}
+	if more > 0 {
+		lines = append(lines, frameLine{skipped: more})
+	}
+	return lines
+}
+
+// line returns the printed line of a single frame with trimmed source path.
+func (f FrameFilter) line(pc uintptr) frameLine {
+	function, file, line := Frame(pc).location()
+	return frameLine{function, f.trimPath(funcPackage(function), file), line, 0, "", file}
+}
+
+func (f FrameFilter) trimPath(pkg, file string) string {
+	if f.TrimPaths && pkg != "" && file != "unknown" {
+		return pkg + "/" + path.Base(file)
+	}
+	for _, prefix := range f.TrimPrefixes {
+		if strings.HasPrefix(file, prefix) {
+			return strings.TrimPrefix(file[len(prefix):], "/")
+		}
+	}
+	return file
+}
+
+func matchPackage(patterns []string, pkg string) bool {
+	_, ok := matchingPattern(patterns, pkg)
+	return ok
+}
+
+// matchingPattern returns the first pattern matching the package path.
+func matchingPattern(patterns []string, pkg string) (string, bool) {
+	for _, pattern := range patterns {
+		if prefix := strings.TrimSuffix(pattern, "/..."); prefix != pattern {
+			if pkg == prefix || strings.HasPrefix(pkg, prefix+"/") {
+				return prefix, true
+			}
+		} else if pkg == pattern {
+			return pattern, true
+		}
+	}
+	return "", false
+}
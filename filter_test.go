@@ -0,0 +1,159 @@
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func setStackFilter(t *testing.T, filter FrameFilter) {
+	old := StackFilter
+	StackFilter = filter
+	t.Cleanup(func() { StackFilter = old })
+}
+
+func recursiveTestError(depth int) Error {
+	if depth > 0 {
+		return recursiveTestError(depth - 1)
+	}
+	return New("recursive").Trace().Make()
+}
+
+func TestFilterTrimPaths(t *testing.T) {
+	setStackFilter(t, FrameFilter{TrimPaths: true})
+	_, file, _, _ := runtime.Caller(0)
+
+	stack := New("trim").Trace().Make().(baseError).renderStack()
+	assert.Contains(t, stack, "\n\tgithub.com/sbreitf1/errors/filter_test.go:")
+	assert.Contains(t, stack, "\n\ttesting/testing.go:")
+	assert.NotContains(t, stack, file)
+}
+
+func TestFilterTrimPrefixes(t *testing.T) {
+	_, file, _, _ := runtime.Caller(0)
+	dir := file[:strings.LastIndex(file, "/")]
+	setStackFilter(t, FrameFilter{TrimPrefixes: []string{dir}})
+
+	stack := New("trim").Trace().Make().(baseError).renderStack()
+	assert.Contains(t, stack, "\n\tfilter_test.go:")
+	assert.NotContains(t, stack, dir)
+}
+
+func TestFilterHide(t *testing.T) {
+	setStackFilter(t, FrameFilter{Hide: []string{"runtime", "testing/..."}})
+
+	stack := New("hide").Trace().Make().(baseError).renderStack()
+	assert.True(t, strings.HasPrefix(stack, "github.com/sbreitf1/errors.TestFilterHide\n\t"))
+	assert.NotContains(t, stack, "testing.tRunner")
+	assert.NotContains(t, stack, "runtime.goexit")
+	assert.NotContains(t, stack, " more")
+}
+
+func TestFilterCollapse(t *testing.T) {
+	setStackFilter(t, FrameFilter{Collapse: []string{"github.com/sbreitf1/errors", "testing"}})
+
+	lines := strings.Split(recursiveTestError(3).(baseError).renderStack(), "\n")
+	if !assert.Len(t, lines, 4) {
+		return
+	}
+	assert.Equal(t, "\t... 5 frames of github.com/sbreitf1/errors", lines[0])
+	assert.Equal(t, "\t... 1 frames of testing", lines[1])
+	assert.Equal(t, "runtime.goexit", lines[2])
+}
+
+func TestFilterMaxDepth(t *testing.T) {
+	setStackFilter(t, FrameFilter{MaxDepth: 2})
+
+	pcs := callers(0)
+	lines := strings.Split(New("depth").Trace().Make().(baseError).renderStack(), "\n")
+	if !assert.Len(t, lines, 5) {
+		return
+	}
+	assert.Equal(t, "github.com/sbreitf1/errors.TestFilterMaxDepth", lines[0])
+	assert.Equal(t, fmt.Sprintf("\t... %d more", len(pcs)-2), lines[4])
+}
+
+func TestFilterMaxDepthChain(t *testing.T) {
+	setStackFilter(t, FrameFilter{Hide: []string{"runtime"}, MaxDepth: 1})
+
+	stack := New("handler").Trace().Make().Cause(serviceTestError()).(baseError).renderStack()
+	assert.Equal(t, 3, strings.Count(stack, " more"), "Every level should be truncated")
+	assert.Equal(t, 3, strings.Count(stack, "\n\t/"))
+}
+
+func TestFilterFormat(t *testing.T) {
+	setStackFilter(t, FrameFilter{TrimPaths: true, Hide: []string{"testing"}})
+
+	str := fmt.Sprintf("%+v", New("format").Trace().Make())
+	assert.Contains(t, str, "\n\tgithub.com/sbreitf1/errors/filter_test.go:")
+	assert.NotContains(t, str, "testing.tRunner")
+}
+
+func TestFilterForceLog(t *testing.T) {
+	setStackFilter(t, FrameFilter{Hide: []string{"testing"}})
+
+	lb := setLogBuffer()
+	New("log").Trace().Make().ForceLog()
+	assert.Contains(t, lb.String(), "TestFilterForceLog")
+	assert.NotContains(t, lb.String(), "testing.tRunner")
+}
+
+func TestJSONStack(t *testing.T) {
+	setStackFilter(t, FrameFilter{TrimPaths: true, Hide: []string{"runtime", "testing"}})
+
+	data, err := json.Marshal(New("handler").Msg("handler failed").Trace().Make().Cause(loadTestError()).(baseError).toJSON(FormatOptions{Stack: true}))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var result struct {
+		Type    string
+		Message string
+		ID      string
+		Stack   []struct {
+			Cause  string
+			Frames []string
+		}
+	}
+	if !assert.NoError(t, json.Unmarshal(data, &result)) {
+		return
+	}
+	assert.Equal(t, "handler", result.Type)
+	assert.Equal(t, "handler failed: load", result.Message)
+	if !assert.Len(t, result.Stack, 2) {
+		return
+	}
+	assert.Equal(t, "", result.Stack[0].Cause)
+	if !assert.Len(t, result.Stack[0].Frames, 2) {
+		return
+	}
+	assert.True(t, strings.HasPrefix(result.Stack[0].Frames[0], "github.com/sbreitf1/errors.TestJSONStack github.com/sbreitf1/errors/filter_test.go:"))
+	assert.Equal(t, "... 2 more", result.Stack[0].Frames[1], "Shared frames are counted including hidden ones")
+	assert.Equal(t, "load", result.Stack[1].Cause)
+	assert.True(t, strings.HasPrefix(result.Stack[1].Frames[0], "github.com/sbreitf1/errors.loadTestError github.com/sbreitf1/errors/stack_test.go:"))
+	assert.Len(t, result.Stack[1].Frames, 2)
+}
+
+func TestMarshalJSONSafeOnly(t *testing.T) {
+	data, err := json.Marshal(New("secret %s").TagStr("owner", "billing").Trace().Make().Args("password").With("user", "admin").Cause(loadTestError()))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Contains(t, string(data), `"message":"`+GenericSafeErrorMessage+`"`)
+	assert.NotContains(t, string(data), "password")
+	assert.NotContains(t, string(data), "admin")
+	assert.NotContains(t, string(data), "load")
+	assert.NotContains(t, string(data), "stack")
+	assert.NotContains(t, string(data), "billing")
+
+	data, err = json.Marshal(New("not found").API(404, 10).Make())
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Contains(t, string(data), `"message":"not found"`)
+	assert.Contains(t, string(data), `"errCode":10`)
+}
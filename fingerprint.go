@@ -0,0 +1,74 @@
+package errors
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// FingerprintTag is the name of a string tag that replaces the stack frames in fingerprints of errors. Use it to group errors of different call sites like TagStr(FingerprintTag, "database-down").
+const FingerprintTag = "fingerprint"
+
+var (
+	// FingerprintFrames is the number of top stack frames included in fingerprints.
+	FingerprintFrames = 3
+
+	// FingerprintPackages contains package patterns of frames included in fingerprints like "github.com/foo/app/...". All frames outside of the standard library are included if empty.
+	FingerprintPackages []string
+)
+
+// Fingerprint returns a stable hash of the error type, the types of all causes and the function names of the top in-project stack frames. Messages, args and ids are not included, so all occurrences of the same problem share the fingerprint.
+func (err baseError) Fingerprint() string {
+	h := sha1.New()
+	fmt.Fprintf(h, "%s", err.errType)
+	for cause := err.content.cause; cause != nil; {
+		fmt.Fprintf(h, "|%s", cause.GetType())
+		cause, _ = cause.Unwrap().(Error)
+	}
+
+	if value, ok := err.GetTagStr(FingerprintTag); ok {
+		fmt.Fprintf(h, "#%s", value)
+	} else {
+		for _, function := range fingerprintFrames(err.trace.stack) {
+			fmt.Fprintf(h, "#%s", function)
+		}
+	}
+	hash := h.Sum(nil)
+	return fmt.Sprintf("%x", hash[:8])
+}
+
+// fingerprintFrames returns the function names of the top FingerprintFrames in-project frames.
+func fingerprintFrames(pcs []uintptr) []string {
+	var functions []string
+	for _, pc := range pcs {
+		if len(functions) >= FingerprintFrames {
+			break
+		}
+		function, file, _ := Frame(pc).location()
+		if len(FingerprintPackages) > 0 {
+			if !matchPackage(FingerprintPackages, funcPackage(function)) {
+				continue
+			}
+		} else if isStdFile(file) {
+			continue
+		}
+		functions = append(functions, function)
+	}
+	return functions
+}
+
+// stdSourceDir is the source directory of the standard library with trailing slash. Empty if the binary has been built using -trimpath.
+var stdSourceDir = func() string {
+	if goroot := runtime.GOROOT(); goroot != "" {
+		return path.Join(filepath.ToSlash(goroot), "src") + "/"
+	}
+	return ""
+}()
+
+// isStdFile returns true for source files of the standard library located in GOROOT. Package paths are not sufficient, because modules like "myservice/storage" do not need to contain a dot either.
+func isStdFile(file string) bool {
+	return stdSourceDir != "" && strings.HasPrefix(file, stdSourceDir)
+}
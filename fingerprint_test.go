@@ -0,0 +1,78 @@
+package errors
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var fingerprintTestTemplate = New("Query %q failed").Trace()
+
+func fingerprintTestError(query string) Error {
+	return fingerprintTestTemplate.Make().Args(query).Cause(fmt.Errorf("connection refused"))
+}
+
+func TestFingerprint(t *testing.T) {
+	var errs []Error
+	for i := 0; i < 3; i++ {
+		errs = append(errs, fingerprintTestError(fmt.Sprintf("SELECT %d", i)))
+	}
+	assert.Len(t, errs[0].Fingerprint(), 16)
+	assert.NotEqual(t, errs[0].GetID(), errs[1].GetID())
+	assert.Equal(t, errs[0].Fingerprint(), errs[1].Fingerprint(), "Args and ids should not change the fingerprint")
+	assert.Equal(t, errs[0].Fingerprint(), errs[2].Msg("other message").Fingerprint())
+
+	// different call site
+	assert.NotEqual(t, errs[0].Fingerprint(), fingerprintTestTemplate.Make().Args("SELECT 1").Cause(fmt.Errorf("connection refused")).Fingerprint())
+	// different cause type
+	assert.NotEqual(t, errs[0].Fingerprint(), errs[0].Cause(New("timeout").Make()).Fingerprint())
+	// different type
+	assert.NotEqual(t, New("a").Make().Fingerprint(), New("b").Make().Fingerprint())
+}
+
+func TestFingerprintTag(t *testing.T) {
+	template := New("Database unavailable").TagStr(FingerprintTag, "database-down")
+	err1 := template.Make()
+	err2 := template.Make()
+	assert.Equal(t, err1.Fingerprint(), err2.Fingerprint(), "Tagged errors of different call sites should share the fingerprint")
+	assert.NotEqual(t, err1.Fingerprint(), New("Database unavailable").Make().Fingerprint())
+}
+
+func TestFingerprintFrames(t *testing.T) {
+	pcs := callers(0)
+	assert.Equal(t, []string{"github.com/sbreitf1/errors.TestFingerprintFrames"}, fingerprintFrames(pcs), "Frames of the standard library should be ignored")
+
+	FingerprintPackages = []string{"testing"}
+	defer func() { FingerprintPackages = nil }()
+	assert.Equal(t, []string{"testing.tRunner"}, fingerprintFrames(pcs))
+}
+
+func TestIsStdFile(t *testing.T) {
+	_, file, _, _ := runtime.Caller(0)
+	assert.False(t, isStdFile(file))
+	assert.False(t, isStdFile("/home/dev/myservice/storage/db.go"), "Packages of modules without dot should not be treated as standard library")
+	_, file, _ = Frame(callers(0)[1]).location()
+	assert.True(t, isStdFile(file), "Frames of package testing should be treated as standard library: %s", file)
+}
+
+func TestFingerprintDotlessModule(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds the module testdata/dotless")
+	}
+	cmd := exec.Command("go", "run", ".")
+	cmd.Dir = "testdata/dotless"
+	cmd.Env = append(os.Environ(), "GOFLAGS=-mod=readonly")
+	out, err := cmd.CombinedOutput()
+	if !assert.NoError(t, err, string(out)) {
+		return
+	}
+	fingerprints := strings.Fields(string(out))
+	if assert.Len(t, fingerprints, 2) {
+		assert.NotEqual(t, fingerprints[0], fingerprints[1], "Call sites in module myservice should not be skipped as standard library")
+	}
+}
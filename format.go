@@ -0,0 +1,211 @@
+package errors
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/sbreitf1/errors/internal/msgformat"
+)
+
+// checkFormat reports all mismatches between the format verbs and the given args as Diagnostic.
+func checkFormat(spec *msgformat.Spec, errType ErrorType, format string, args []interface{}) {
+	if spec.Problem != "" {
+		reportDiagnostic(DiagnosticInvalidFormat, errType, format, "%s", spec.Problem)
+		return
+	}
+	if len(args) < spec.ArgCount {
+		reportDiagnostic(DiagnosticArgCount, errType, format, "expected %d args but got %d", spec.ArgCount, len(args))
+	} else if len(args) > spec.ArgCount && !spec.Reordered {
+		reportDiagnostic(DiagnosticArgCount, errType, format, "expected %d args but got %d", spec.ArgCount, len(args))
+	}
+
+	for _, v := range spec.Verbs {
+		if v.Arg >= len(args) {
+			continue
+		}
+		if !verbAccepts(v.Verb, args[v.Arg]) {
+			reportDiagnostic(DiagnosticArgType, errType, format, "verb %%%c does not accept arg %d of type %T", v.Verb, v.Arg+1, args[v.Arg])
+		}
+	}
+}
+
+// verbAccepts returns true if fmt is able to print the given arg using verb.
+func verbAccepts(verb rune, arg interface{}) bool {
+	switch verb {
+	case 'v', 'T':
+		return true
+	case '*':
+		// width and precision need to be integers
+		return arg != nil && reflect.TypeOf(arg).Kind() >= reflect.Int && reflect.TypeOf(arg).Kind() <= reflect.Uintptr
+	}
+
+	if arg == nil {
+		return false
+	}
+	if _, ok := arg.(fmt.Formatter); ok {
+		return true
+	}
+	if verb == 's' || verb == 'q' || verb == 'x' || verb == 'X' {
+		if _, ok := arg.(error); ok {
+			return true
+		}
+		if _, ok := arg.(fmt.Stringer); ok {
+			return true
+		}
+	}
+	return verbAcceptsType(verb, reflect.TypeOf(arg), 0)
+}
+
+func verbAcceptsType(verb rune, t reflect.Type, level int) bool {
+	switch t.Kind() {
+	case reflect.Bool:
+		return verb == 't'
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strings.ContainsRune("bcdoOqxXU", verb)
+	case reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		return strings.ContainsRune("beEfFgGxX", verb)
+	case reflect.String:
+		return strings.ContainsRune("sqxX", verb)
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 && strings.ContainsRune("sqxX", verb) {
+			return true
+		}
+		if verb == 'p' {
+			return t.Kind() == reflect.Slice
+		}
+		return level < 4 && verbAcceptsType(verb, t.Elem(), level+1)
+	case reflect.Ptr:
+		if level == 0 && strings.ContainsRune("pbdoxX", verb) {
+			return true
+		}
+		// pointers to structs, arrays, slices and maps are printed like the underlying value
+		if level == 0 {
+			switch t.Elem().Kind() {
+			case reflect.Struct, reflect.Array, reflect.Slice, reflect.Map:
+				return verbAcceptsType(verb, t.Elem(), level+1)
+			}
+		}
+		return strings.ContainsRune("pbdoxX", verb)
+	case reflect.Chan, reflect.Func, reflect.UnsafePointer:
+		return strings.ContainsRune("pbdoxX", verb)
+	case reflect.Map:
+		if verb == 'p' {
+			return true
+		}
+		return level < 4 && verbAcceptsType(verb, t.Key(), level+1) && verbAcceptsType(verb, t.Elem(), level+1)
+	default:
+		// structs and interfaces are printed field-wise, which is not checked here
+		return true
+	}
+}
+
+// withMessage returns a copy of the content with replaced message. Args are only retained for later formatting if not empty.
+func (c content) withMessage(errType ErrorType, msg string, args []interface{}) content {
+	c.message = msg
+	c.spec = msgformat.Parse(msg)
+	c.args = nil
+	if len(args) > 0 {
+		return c.withArgs(errType, args)
+	}
+	return c
+}
+
+// withArgs returns a copy of the content with args for the message format. Mismatching args and repeated calls are reported as Diagnostic. Args of repeated calls are ignored.
+func (c content) withArgs(errType ErrorType, args []interface{}) content {
+	if c.args != nil {
+		reportDiagnostic(DiagnosticArgsReapplied, errType, c.message, "args have already been applied")
+		return c
+	}
+	if c.spec == nil {
+		c.spec = msgformat.Parse(c.message)
+	}
+	checkFormat(c.spec, errType, c.message, args)
+	if args == nil {
+		args = []interface{}{}
+	}
+	c.args = args
+	return c
+}
+
+// text returns the message with all fields and args applied.
+func (c content) text() string {
+	if c.args == nil {
+		return replacePlaceholders(c.message, c.fields, false)
+	}
+	// hack: go-vet erroneously detects missing args when calling Sprintf directly
+	// -> using the encapsulation prevents go-vet from processing the format string
+	return fmt.Sprintf(fmt.Sprintf("%s", replacePlaceholders(c.message, c.fields, true)), c.args...)
+}
+
+// withField returns a copy of the content with an additional named field.
+func (c content) withField(name string, value interface{}) content {
+	fields := make(map[string]interface{}, len(c.fields)+1)
+	for k, v := range c.fields {
+		fields[k] = v
+	}
+	fields[name] = value
+	c.fields = fields
+	return c
+}
+
+// fieldsString returns all fields as sorted list of key-value pairs.
+func (c content) fieldsString() string {
+	names := make([]string, 0, len(c.fields))
+	for name := range c.fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var sb strings.Builder
+	for i, name := range names {
+		if i > 0 {
+			sb.WriteString(" ")
+		}
+		sb.WriteString(name)
+		sb.WriteString("=")
+		sb.WriteString(fieldString(c.fields[name], true))
+	}
+	return sb.String()
+}
+
+func fieldString(value interface{}, quote bool) string {
+	if str, ok := value.(string); ok {
+		if quote {
+			return strconv.Quote(str)
+		}
+		return str
+	}
+	return fmt.Sprint(value)
+}
+
+// replacePlaceholders replaces all named placeholders like {name} by the corresponding field value. Unknown placeholders are retained. Percent signs in values are escaped if the result is used as format string.
+func replacePlaceholders(msg string, fields map[string]interface{}, escapePercent bool) string {
+	if len(fields) == 0 || !strings.Contains(msg, "{") {
+		return msg
+	}
+
+	var sb strings.Builder
+	for {
+		start, end := msgformat.NextPlaceholder(msg)
+		if start < 0 {
+			sb.WriteString(msg)
+			return sb.String()
+		}
+
+		sb.WriteString(msg[:start])
+		if value, ok := fields[msg[start+1:end-1]]; ok {
+			str := fieldString(value, false)
+			if escapePercent {
+				str = strings.Replace(str, "%", "%%", -1)
+			}
+			sb.WriteString(str)
+		} else {
+			sb.WriteString(msg[start:end])
+		}
+		msg = msg[end:]
+	}
+}
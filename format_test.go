@@ -0,0 +1,136 @@
+package errors
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNamedPlaceholders(t *testing.T) {
+	err := New("Argument {name} is not valid").Make().With("name", "foo")
+	assert.Equal(t, "Argument foo is not valid", err.Error())
+	assert.Equal(t, ErrorType("Argument {name} is not valid"), err.GetType())
+
+	val, ok := err.GetField("name")
+	assert.True(t, ok)
+	assert.Equal(t, "foo", val)
+	_, ok = err.GetField("foobar")
+	assert.False(t, ok)
+	assert.Equal(t, map[string]interface{}{"name": "foo"}, err.GetFields())
+}
+
+func TestNamedPlaceholdersMissing(t *testing.T) {
+	err := New("Argument {name} is {state}").Make().With("state", 42)
+	assert.Equal(t, "Argument {name} is 42", err.Error())
+}
+
+func TestNamedPlaceholdersNoIdentifier(t *testing.T) {
+	err := New("JSON {\"name\": {name}} {} {1x}").Make().With("name", 1)
+	assert.Equal(t, "JSON {\"name\": 1} {} {1x}", err.Error())
+}
+
+func TestNamedPlaceholdersWithArgs(t *testing.T) {
+	err := New("{count}%% of %s are {state}").With("state", "100%").Make().Args("foo").With("count", 42)
+	assert.Equal(t, "42% of foo are 100%", err.Error())
+}
+
+func TestNamedPlaceholdersImmutable(t *testing.T) {
+	tmpl := New("{a} {b}").With("a", 1)
+	err1 := tmpl.Make().With("b", 2)
+	err2 := tmpl.Make().With("b", 3)
+	assert.Equal(t, "1 2", err1.Error())
+	assert.Equal(t, "1 3", err2.Error())
+	assert.Equal(t, "1 {b}", tmpl.Make().Error())
+}
+
+func TestNamedPlaceholdersSafe(t *testing.T) {
+	err := New("Argument {name} is not valid").Safe().Untrack().Make().With("name", "foo")
+	assert.Equal(t, "Argument foo is not valid", err.API().Message)
+
+	err = err.Msg("Unsafe {name}")
+	assert.Equal(t, "", err.SafeString())
+}
+
+func TestNamedPlaceholdersLocalized(t *testing.T) {
+	defer resetMessages()
+	tmpl := New("Argument {name} is not valid").Safe().Untrack()
+	Messages.Add("de", tmpl, "Argument {name} ist ungültig")
+	assert.Equal(t, "Argument foo ist ungültig", tmpl.Make().With("name", "foo").LocalizedAPI("de").Message)
+}
+
+func TestNamedPlaceholdersLog(t *testing.T) {
+	err := New("Argument {name} is not valid").Make().With("name", "foo").With("count", 3)
+	lb := setLogBuffer()
+	err.ToLog()
+	assert.True(t, strings.Contains(lb.String(), "count=3 name=\"foo\""))
+}
+func TestArgsArity(t *testing.T) {
+	diagnostics := captureDiagnostics()
+	defer resetDiagnostics()
+
+	New("foo %s %d").Make().Args("bar")
+	New("foo %s").Args("bar", 5)
+	New("foo %[2]s %[1]s").Args("bar", "baz")
+	New("foo %s", "bar", "baz")
+	assert.Equal(t, []DiagnosticKind{DiagnosticArgCount, DiagnosticArgCount, DiagnosticArgCount}, diagnostics.kinds())
+	assert.Equal(t, ErrorType("foo %s %d"), (*diagnostics)[0].Type)
+}
+
+func TestArgsType(t *testing.T) {
+	diagnostics := captureDiagnostics()
+	defer resetDiagnostics()
+
+	New("%d %s %v %s %x %f %t %d").Args(1, "str", struct{}{}, New("error").Make(), []byte("bytes"), 4.2, true, []int{1, 2})
+	assert.Equal(t, []DiagnosticKind(nil), diagnostics.kinds())
+
+	New("%d").Args("str")
+	New("%s").Args(42)
+	New("%f").Args(nil)
+	assert.Equal(t, []DiagnosticKind{DiagnosticArgType, DiagnosticArgType, DiagnosticArgType}, diagnostics.kinds())
+}
+
+func TestArgsReapplied(t *testing.T) {
+	diagnostics := captureDiagnostics()
+	defer resetDiagnostics()
+
+	err := New("foo %v").Make().Args("bar").Args("baz")
+	assert.Equal(t, "foo bar", err.Error())
+	err = New("test").Msg("foo %v", "bar").Make().Args("baz")
+	assert.Equal(t, "foo bar", err.Error())
+	assert.Equal(t, []DiagnosticKind{DiagnosticArgsReapplied, DiagnosticArgsReapplied}, diagnostics.kinds())
+}
+
+func TestDiagnosticsPanicInTestMode(t *testing.T) {
+	CurrentMode = TestMode
+	defer func() { CurrentMode = ProductionMode }()
+
+	assert.Panics(t, func() { New("foo %s").Args() })
+	assert.NotPanics(t, func() { New("foo %s").Args("bar") })
+}
+
+/* ############################################# */
+/* ###                Helper                 ### */
+/* ############################################# */
+
+type diagnosticList []Diagnostic
+
+func (l *diagnosticList) kinds() []DiagnosticKind {
+	var kinds []DiagnosticKind
+	for _, d := range *l {
+		kinds = append(kinds, d.Kind)
+	}
+	return kinds
+}
+
+func captureDiagnostics() *diagnosticList {
+	list := &diagnosticList{}
+	DiagnosticHandler = func(d Diagnostic) {
+		*list = append(*list, d)
+	}
+	return list
+}
+
+func resetDiagnostics() {
+	DiagnosticHandler = nil
+}
@@ -0,0 +1,87 @@
+package errors
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// FormatOptions control the verbosity of formatted records.
+type FormatOptions struct {
+	// Stack includes stack traces of the error and all traced causes.
+	Stack bool
+	// SafeOnly restricts the output to the safe message, id and severity. Unsafe messages are replaced by GenericSafeErrorMessage.
+	SafeOnly bool
+}
+
+// Formatter encodes a record for output by sinks.
+type Formatter func(record Record) []byte
+
+// TextFormatter returns a formatter for the default log lines like "[ERR id] message". Each line is terminated by a line break.
+func TextFormatter(options FormatOptions) Formatter {
+	return func(record Record) []byte {
+		var buf bytes.Buffer
+		writeLogLines(record, options, func(severity Severity, msg string, args ...interface{}) {
+			fmt.Fprintf(&buf, msg, args...)
+			buf.WriteByte('\n')
+		})
+		return buf.Bytes()
+	}
+}
+
+// JSONFormatter returns a formatter that encodes records as single line JSON object terminated by a line break. Values of tags and fields that cannot be encoded as JSON, like NaN or functions, are replaced by their fmt.Sprint representation.
+func JSONFormatter(options FormatOptions) Formatter {
+	return func(record Record) []byte {
+		data, _ := encodeJSON(record, options)
+		return data
+	}
+}
+
+// encodeJSON encodes the record like JSONFormatter and returns the encoding error of replaced values.
+func encodeJSON(record Record, options FormatOptions) ([]byte, error) {
+	var j jsonError
+	if err, ok := record.Error.(baseError); ok {
+		j = err.toJSON(options)
+	} else {
+		j = jsonError{Type: record.Error.GetType(), Message: record.Error.Error(), SafeMessage: safeMessage(record.Error), ID: record.Error.GetID(), CreatedAt: record.Error.CreatedAt()}
+		if options.SafeOnly {
+			j.Message = j.SafeMessage
+		}
+	}
+	j.Severity = record.Severity
+	data, err := json.Marshal(jsonRecord{record.Time, j})
+	if err != nil {
+		j.Tags, j.Fields = jsonValues(j.Tags), jsonValues(j.Fields)
+		data, _ = json.Marshal(jsonRecord{record.Time, j})
+	}
+	return append(data, '\n'), err
+}
+
+// jsonValues returns a copy of values with all values that cannot be encoded as JSON replaced by their fmt.Sprint representation.
+func jsonValues(values map[string]interface{}) map[string]interface{} {
+	if values == nil {
+		return nil
+	}
+	result := make(map[string]interface{}, len(values))
+	for key, val := range values {
+		if _, err := json.Marshal(val); err != nil {
+			val = fmt.Sprint(val)
+		}
+		result[key] = val
+	}
+	return result
+}
+
+type jsonRecord struct {
+	Time time.Time `json:"time"`
+	jsonError
+}
+
+// safeMessage returns the safe message of the error or GenericSafeErrorMessage, if the error is not safe.
+func safeMessage(err Error) string {
+	if msg := err.SafeString(); msg != "" {
+		return msg
+	}
+	return GenericSafeErrorMessage
+}
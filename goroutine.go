@@ -0,0 +1,112 @@
+package errors
+
+import (
+	"context"
+	"fmt"
+	"runtime/pprof"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// CaptureGoroutine enables recording of the goroutine id and the creating goroutine on instantiation of errors.
+var CaptureGoroutine = false
+
+// Goroutine describes the execution context an error has been created in.
+type Goroutine struct {
+	// ID is the id of the goroutine that created the error. Zero if CaptureGoroutine is disabled.
+	ID int64 `json:"id,omitempty"`
+	// CreatedBy is the function that started the goroutine. Empty for the main goroutine.
+	CreatedBy string `json:"createdBy,omitempty"`
+	// CreatedAt is the source location "file:line" of the go statement.
+	CreatedAt string `json:"createdAt,omitempty"`
+	// CreatorID is the id of the goroutine that executed the go statement. Zero if not reported by the runtime.
+	CreatorID int64 `json:"creatorId,omitempty"`
+	// Labels contains the pprof labels supplied by Labels(ctx).
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+func (g Goroutine) String() string {
+	var sb strings.Builder
+	if g.ID > 0 {
+		sb.WriteString(strconv.FormatInt(g.ID, 10))
+	}
+	if g.CreatedBy != "" {
+		fmt.Fprintf(&sb, " created by %s", g.CreatedBy)
+		if g.CreatorID > 0 {
+			fmt.Fprintf(&sb, " in goroutine %d", g.CreatorID)
+		}
+		if g.CreatedAt != "" {
+			fmt.Fprintf(&sb, " at %s", g.CreatedAt)
+		}
+	}
+	if len(g.Labels) > 0 {
+		keys := make([]string, 0, len(g.Labels))
+		for key := range g.Labels {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		sb.WriteString(" labels:")
+		for _, key := range keys {
+			fmt.Fprintf(&sb, " %s=%s", key, strconv.Quote(g.Labels[key]))
+		}
+	}
+	return strings.TrimPrefix(sb.String(), " ")
+}
+
+// parseGoroutine reads the goroutine header and the created by frame from the output of debug.Stack().
+func parseGoroutine(stack string) *Goroutine {
+	lines := strings.Split(stack, "\n")
+	var g Goroutine
+	if fields := strings.Fields(lines[0]); len(fields) >= 2 && fields[0] == "goroutine" {
+		g.ID, _ = strconv.ParseInt(fields[1], 10, 64)
+	}
+	for i, line := range lines {
+		if !strings.HasPrefix(line, "created by ") {
+			continue
+		}
+		createdBy := strings.TrimPrefix(line, "created by ")
+		if pos := strings.Index(createdBy, " in goroutine "); pos >= 0 {
+			g.CreatorID, _ = strconv.ParseInt(createdBy[pos+len(" in goroutine "):], 10, 64)
+			createdBy = createdBy[:pos]
+		}
+		g.CreatedBy = createdBy
+		if i+1 < len(lines) {
+			location := strings.TrimSpace(lines[i+1])
+			if pos := strings.LastIndex(location, " +0x"); pos >= 0 {
+				location = location[:pos]
+			}
+			g.CreatedAt = location
+		}
+		break
+	}
+	return &g
+}
+
+func (err baseError) GetGoroutine() (Goroutine, bool) {
+	if err.trace.goroutine == nil {
+		return Goroutine{}, false
+	}
+	g := *err.trace.goroutine
+	if g.Labels != nil {
+		g.Labels = make(map[string]string, len(err.trace.goroutine.Labels))
+		for key, val := range err.trace.goroutine.Labels {
+			g.Labels[key] = val
+		}
+	}
+	return g, true
+}
+
+func (err baseError) Labels(ctx context.Context) Error {
+	g, _ := err.GetGoroutine()
+	pprof.ForLabels(ctx, func(key, value string) bool {
+		if g.Labels == nil {
+			g.Labels = make(map[string]string)
+		}
+		g.Labels[key] = value
+		return true
+	})
+	trace := err.trace
+	trace.goroutine = &g
+	return baseError{err.errType, err.parents, err.content, err.flags, trace, err.api}
+}
@@ -0,0 +1,85 @@
+package errors
+
+import (
+	"context"
+	"encoding/json"
+	"runtime/pprof"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func setCaptureGoroutine(t *testing.T, capture bool) {
+	old := CaptureGoroutine
+	CaptureGoroutine = capture
+	t.Cleanup(func() { CaptureGoroutine = old })
+}
+
+func TestParseGoroutine(t *testing.T) {
+	g := parseGoroutine("goroutine 42 [running]:\nmain.worker()\n\t/app/main.go:20 +0x1d\ncreated by main.main in goroutine 1\n\t/app/main.go:10 +0x25")
+	assert.Equal(t, Goroutine{42, "main.main", "/app/main.go:10", 1, nil}, *g)
+
+	g = parseGoroutine("goroutine 7 [running]:\nmain.worker()\n\t/app/main.go:20 +0x1d\ncreated by main.main\n\t/app/main.go:10 +0x25\n")
+	assert.Equal(t, Goroutine{7, "main.main", "/app/main.go:10", 0, nil}, *g)
+
+	assert.Equal(t, Goroutine{1, "", "", 0, nil}, *parseGoroutine("goroutine 1 [running]:\nmain.main()\n\t/app/main.go:5 +0x1d\n"))
+}
+
+func TestCaptureGoroutine(t *testing.T) {
+	_, ok := New("plain").Make().GetGoroutine()
+	assert.False(t, ok)
+
+	setCaptureGoroutine(t, true)
+	result := make(chan Error)
+	go func() {
+		result <- New("async").Make()
+	}()
+	g, ok := (<-result).GetGoroutine()
+	assert.True(t, ok)
+	assert.True(t, g.ID > 0)
+	assert.Equal(t, "github.com/sbreitf1/errors.TestCaptureGoroutine", g.CreatedBy)
+	assert.True(t, strings.Contains(g.CreatedAt, "goroutine_test.go:"))
+}
+
+func TestLabels(t *testing.T) {
+	ctx := pprof.WithLabels(context.Background(), pprof.Labels("tenant", "acme", "worker", "3"))
+	err := New("labeled").Make()
+	labeled := err.Labels(ctx)
+
+	_, ok := err.GetGoroutine()
+	assert.False(t, ok, "Labels should return a copy")
+	g, ok := labeled.GetGoroutine()
+	assert.True(t, ok)
+	assert.Equal(t, map[string]string{"tenant": "acme", "worker": "3"}, g.Labels)
+	assert.Equal(t, `labels: tenant="acme" worker="3"`, g.String())
+
+	// returned labels are a copy
+	g.Labels["tenant"] = "other"
+	g, _ = labeled.GetGoroutine()
+	assert.Equal(t, "acme", g.Labels["tenant"])
+
+	// labels are kept for all error mutations
+	g, ok = labeled.Msg("changed").With("id", 1).GetGoroutine()
+	assert.True(t, ok)
+	assert.Equal(t, "acme", g.Labels["tenant"])
+}
+
+func TestGoroutineLog(t *testing.T) {
+	setCaptureGoroutine(t, true)
+	var err Error
+	pprof.Do(context.Background(), pprof.Labels("job", "import"), func(ctx context.Context) {
+		err = New("job failed").Make().Labels(ctx)
+	})
+
+	lb := setLogBuffer()
+	err.ForceLog()
+	assert.Contains(t, lb.String(), "[GOROUTINE "+err.GetID()+"] ")
+	assert.Contains(t, lb.String(), " created by testing.(*T).Run")
+	assert.Contains(t, lb.String(), ` labels: job="import"`)
+
+	data, jsonErr := json.Marshal(err.(baseError).toJSON(FormatOptions{}))
+	assert.NoError(t, jsonErr)
+	assert.Contains(t, string(data), `"labels":{"job":"import"}`)
+	assert.Contains(t, string(data), `"createdBy":"testing.(*T).Run"`)
+}
@@ -0,0 +1,84 @@
+// Package gosrc provides helpers to locate Go packages and source positions for the code generation and migration tools.
+package gosrc
+
+import (
+	"bufio"
+	"fmt"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ImportPath determines the import path of dir using the module definition in the nearest go.mod.
+func ImportPath(dir string) string {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+	for d := abs; ; d = filepath.Dir(d) {
+		if module := modulePath(filepath.Join(d, "go.mod")); module != "" {
+			rel, err := filepath.Rel(d, abs)
+			if err != nil || rel == "." {
+				return module
+			}
+			return module + "/" + filepath.ToSlash(rel)
+		}
+		if filepath.Dir(d) == d {
+			return ""
+		}
+	}
+}
+
+func modulePath(goMod string) string {
+	f, err := os.Open(goMod)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if strings.HasPrefix(line, "module ") {
+			return strings.Trim(strings.TrimSpace(line[len("module "):]), "\"")
+		}
+	}
+	return ""
+}
+
+// Position returns a position like "pkg/file.go:12" with the file relative to the parent of the package directory dir.
+func Position(fset *token.FileSet, pos token.Pos, dir string) string {
+	p := fset.Position(pos)
+	file := p.Filename
+	if rel, err := filepath.Rel(dir, file); err == nil {
+		file = filepath.ToSlash(filepath.Join(filepath.Base(dir), rel))
+	}
+	return fmt.Sprintf("%s:%d", file, p.Line)
+}
+
+// ExpandPatterns replaces directory patterns like "./..." by all contained directories with Go files.
+func ExpandPatterns(patterns []string) []string {
+	var expanded []string
+	for _, pattern := range patterns {
+		if !strings.HasSuffix(pattern, "/...") {
+			expanded = append(expanded, pattern)
+			continue
+		}
+
+		root := strings.TrimSuffix(pattern, "/...")
+		filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+			if err != nil || !info.IsDir() {
+				return nil
+			}
+			name := info.Name()
+			if path != root && (name == "testdata" || name == "vendor" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")) {
+				return filepath.SkipDir
+			}
+			if files, _ := filepath.Glob(filepath.Join(path, "*.go")); len(files) > 0 {
+				expanded = append(expanded, path)
+			}
+			return nil
+		})
+	}
+	return expanded
+}
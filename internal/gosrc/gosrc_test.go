@@ -0,0 +1,27 @@
+package gosrc
+
+import (
+	"go/token"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImportPath(t *testing.T) {
+	assert.Equal(t, "github.com/sbreitf1/errors/internal/gosrc", ImportPath("."))
+	assert.Equal(t, "github.com/sbreitf1/errors", ImportPath("../.."))
+}
+
+func TestPosition(t *testing.T) {
+	fset := token.NewFileSet()
+	dir, _ := filepath.Abs(".")
+	file := fset.AddFile(filepath.Join(dir, "gosrc.go"), -1, 100)
+	file.SetLines([]int{0, 10, 20})
+	assert.Equal(t, "gosrc/gosrc.go:2", Position(fset, file.Pos(15), dir))
+}
+
+func TestExpandPatterns(t *testing.T) {
+	assert.Equal(t, []string{"pkg"}, ExpandPatterns([]string{"pkg"}))
+	assert.Equal(t, []string{"../gosrc", "../msgformat"}, ExpandPatterns([]string{"../..."}))
+}
@@ -0,0 +1,192 @@
+// Package msgformat parses message format strings of the errors package. It is shared by the library and its code generation and analysis tools.
+package msgformat
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Spec describes the verbs of a format string as consumed by fmt.Sprintf.
+type Spec struct {
+	Verbs []Verb
+	// ArgCount denotes the number of args required by the format string.
+	ArgCount int
+	// Reordered is set if explicit argument indexes are used. Superfluous args are not reported in this case.
+	Reordered bool
+	// Problem describes a malformed format string. Empty for valid ones.
+	Problem string
+}
+
+// Verb denotes a verb and the index of the consumed arg. Args consumed by '*' for width and precision are denoted by verb '*'.
+type Verb struct {
+	Verb rune
+	Arg  int
+}
+
+const (
+	sprintfVerbs = "vTtbcdoOqxXUeEfFgGsp"
+	errorfVerbs  = sprintfVerbs + "w"
+)
+
+// Parse analyzes a format string using the same rules as fmt.Sprintf.
+func Parse(format string) *Spec {
+	return parse(format, sprintfVerbs)
+}
+
+// ParseErrorf analyzes a format string using the same rules as fmt.Errorf, which additionally accepts the verb %w.
+func ParseErrorf(format string) *Spec {
+	return parse(format, errorfVerbs)
+}
+
+// Simple returns the verbs in order of their args. False is returned for malformed format strings and if explicit argument indexes or '*' are used.
+func (spec *Spec) Simple() ([]rune, bool) {
+	if spec.Problem != "" || spec.Reordered {
+		return nil, false
+	}
+	verbs := make([]rune, 0, len(spec.Verbs))
+	for _, v := range spec.Verbs {
+		if v.Verb == '*' {
+			return nil, false
+		}
+		verbs = append(verbs, v.Verb)
+	}
+	return verbs, true
+}
+
+func parse(format, known string) *Spec {
+	spec := &Spec{}
+	argNum := 0
+	consume := func(verb rune) {
+		spec.Verbs = append(spec.Verbs, Verb{verb, argNum})
+		argNum++
+		if argNum > spec.ArgCount {
+			spec.ArgCount = argNum
+		}
+	}
+
+	for i := 0; i < len(format); {
+		if format[i] != '%' {
+			i++
+			continue
+		}
+		i++
+
+		// flags
+		for i < len(format) && strings.IndexByte("#0+- ", format[i]) >= 0 {
+			i++
+		}
+		// width
+		i = spec.parseArgIndex(format, i, &argNum)
+		if i < len(format) && format[i] == '*' {
+			consume('*')
+			i++
+		} else {
+			for i < len(format) && format[i] >= '0' && format[i] <= '9' {
+				i++
+			}
+		}
+		// precision
+		if i < len(format) && format[i] == '.' {
+			i++
+			i = spec.parseArgIndex(format, i, &argNum)
+			if i < len(format) && format[i] == '*' {
+				consume('*')
+				i++
+			} else {
+				for i < len(format) && format[i] >= '0' && format[i] <= '9' {
+					i++
+				}
+			}
+		}
+		i = spec.parseArgIndex(format, i, &argNum)
+
+		if i >= len(format) {
+			spec.setProblem("missing verb at end of format string")
+			break
+		}
+		verb, size := utf8.DecodeRuneInString(format[i:])
+		i += size
+		if verb == '%' {
+			continue
+		}
+		if !strings.ContainsRune(known, verb) {
+			spec.setProblem(fmt.Sprintf("unknown verb %%%c", verb))
+		}
+		consume(verb)
+	}
+	return spec
+}
+
+// parseArgIndex parses an explicit argument index like [2] and sets argNum accordingly.
+func (spec *Spec) parseArgIndex(format string, i int, argNum *int) int {
+	if i >= len(format) || format[i] != '[' {
+		return i
+	}
+	spec.Reordered = true
+	end := strings.IndexByte(format[i:], ']')
+	if end < 0 {
+		spec.setProblem("unterminated argument index")
+		return len(format)
+	}
+	index, err := strconv.Atoi(format[i+1 : i+end])
+	if err != nil || index < 1 {
+		spec.setProblem(fmt.Sprintf("invalid argument index %q", format[i:i+end+1]))
+	} else {
+		*argNum = index - 1
+	}
+	return i + end + 1
+}
+
+// setProblem retains the first problem of a format string.
+func (spec *Spec) setProblem(problem string) {
+	if spec.Problem == "" {
+		spec.Problem = problem
+	}
+}
+
+// NextPlaceholder returns the start and end index of the next placeholder like {name} in msg or -1 if there is none.
+func NextPlaceholder(msg string) (int, int) {
+	offset := 0
+	for {
+		start := strings.Index(msg[offset:], "{")
+		if start < 0 {
+			return -1, -1
+		}
+		start += offset
+
+		end := start + 1
+		for end < len(msg) && isPlaceholderChar(msg[end], end == start+1) {
+			end++
+		}
+		if end > start+1 && end < len(msg) && msg[end] == '}' {
+			return start, end + 1
+		}
+		offset = start + 1
+	}
+}
+
+// Placeholders returns the names of all placeholders like {name} in order of their first occurrence.
+func Placeholders(msg string) []string {
+	var names []string
+	known := make(map[string]bool)
+	for {
+		start, end := NextPlaceholder(msg)
+		if start < 0 {
+			return names
+		}
+		if name := msg[start+1 : end-1]; !known[name] {
+			known[name] = true
+			names = append(names, name)
+		}
+		msg = msg[end:]
+	}
+}
+
+func isPlaceholderChar(c byte, first bool) bool {
+	if c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') {
+		return true
+	}
+	return !first && ((c >= '0' && c <= '9') || c == '.' || c == '-')
+}
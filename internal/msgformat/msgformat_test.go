@@ -0,0 +1,39 @@
+package msgformat
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	spec := Parse("%s: %5.2f%% %*d %[1]q {name}")
+	assert.Equal(t, "", spec.Problem)
+	assert.Equal(t, 4, spec.ArgCount)
+	assert.True(t, spec.Reordered)
+	assert.Equal(t, []Verb{{'s', 0}, {'f', 1}, {'*', 2}, {'d', 3}, {'q', 0}}, spec.Verbs)
+
+	assert.Equal(t, 0, Parse("no verbs {at} all").ArgCount)
+	assert.NotEqual(t, "", Parse("trailing %").Problem)
+	assert.NotEqual(t, "", Parse("unknown %y verb").Problem)
+	assert.NotEqual(t, "", Parse("wrapped: %w").Problem)
+	assert.Equal(t, "", ParseErrorf("wrapped: %w").Problem)
+}
+
+func TestSimple(t *testing.T) {
+	verbs, ok := Parse("%d of %-5s: %w%%").Simple()
+	assert.False(t, ok)
+	verbs, ok = ParseErrorf("%d of %-5s: %w%%").Simple()
+	assert.True(t, ok)
+	assert.Equal(t, []rune{'d', 's', 'w'}, verbs)
+
+	_, ok = Parse("%*d").Simple()
+	assert.False(t, ok)
+	_, ok = Parse("%[1]d").Simple()
+	assert.False(t, ok)
+}
+
+func TestPlaceholders(t *testing.T) {
+	assert.Equal(t, []string{"name", "user.id"}, Placeholders("{name} {1st} {user.id} {name} {} {open"))
+	assert.Nil(t, Placeholders("plain"))
+}
@@ -0,0 +1,157 @@
+package errors
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// LimitKey selects how errors are grouped by a LogLimiter.
+type LimitKey int
+
+const (
+	// LimitByType limits logging per error type.
+	LimitByType LimitKey = iota
+	// LimitByFingerprint limits logging per fingerprint, so the same type from different call sites is limited separately.
+	LimitByFingerprint
+)
+
+// Limiter is consulted before an error is written to log. Logging is not limited if nil.
+var Limiter *LogLimiter
+
+// LogLimiter allows a number of full logs per error type or fingerprint and interval. Further occurrences are counted and reported by a single summary line, or a SuppressedError record if LogSink is set, on the next call of Allow after the interval has passed. Windows of error types that did not occur during the last interval are removed. It is safe for concurrent use.
+type LogLimiter struct {
+	burst    int
+	interval time.Duration
+	key      LimitKey
+	now      func() time.Time
+
+	mutex   sync.Mutex
+	windows map[string]*limitWindow
+	// nextExpiry denotes the earliest end of the intervals of all windows.
+	nextExpiry time.Time
+	dropped    int64
+}
+
+type limitWindow struct {
+	errType    ErrorType
+	start      time.Time
+	count      int
+	suppressed int64
+}
+
+// NewLogLimiter returns a limiter that allows burst full logs per error type and interval.
+func NewLogLimiter(burst int, interval time.Duration) *LogLimiter {
+	return &LogLimiter{burst, interval, LimitByType, time.Now, sync.Mutex{}, make(map[string]*limitWindow), time.Time{}, 0}
+}
+
+// ByFingerprint groups errors by fingerprint instead of error type and returns the limiter.
+func (l *LogLimiter) ByFingerprint() *LogLimiter {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.key = LimitByFingerprint
+	return l
+}
+
+// WithClock replaces the time source of the limiter and returns it. Used for tests.
+func (l *LogLimiter) WithClock(now func() time.Time) *LogLimiter {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.now = now
+	return l
+}
+
+// Allow returns true if the error should be logged. Summaries of suppressed occurrences are written to Logger for all error types whose interval has passed, so they do not depend on the same error occurring again.
+func (l *LogLimiter) Allow(err Error) bool {
+	l.mutex.Lock()
+	byFingerprint := l.key == LimitByFingerprint
+	l.mutex.Unlock()
+
+	key := string(err.GetType())
+	if byFingerprint {
+		key = err.Fingerprint()
+	}
+
+	l.mutex.Lock()
+	now := l.now()
+	summaries := l.expire(now)
+	w, ok := l.windows[key]
+	if !ok {
+		w = &limitWindow{err.GetType(), now, 0, 0}
+		l.windows[key] = w
+		if len(l.windows) == 1 {
+			l.nextExpiry = now.Add(l.interval)
+		}
+	}
+	w.count++
+	allow := w.count <= l.burst
+	if !allow {
+		w.suppressed++
+		l.dropped++
+	}
+	l.mutex.Unlock()
+
+	for _, summary := range summaries {
+		summary.log(l.interval)
+	}
+	return allow
+}
+
+// expire removes all windows whose interval has passed and returns the ones with suppressed occurrences ordered by error type. The mutex needs to be locked by the caller.
+func (l *LogLimiter) expire(now time.Time) []*limitWindow {
+	if len(l.windows) == 0 || now.Before(l.nextExpiry) {
+		return nil
+	}
+
+	var expired []*limitWindow
+	var next time.Time
+	for key, w := range l.windows {
+		end := w.start.Add(l.interval)
+		if !now.Before(end) {
+			delete(l.windows, key)
+			if w.suppressed > 0 {
+				expired = append(expired, w)
+			}
+		} else if next.IsZero() || end.Before(next) {
+			next = end
+		}
+	}
+	l.nextExpiry = next
+	sort.Slice(expired, func(i, j int) bool { return expired[i].errType < expired[j].errType })
+	return expired
+}
+
+// Dropped returns the total number of suppressed logs.
+func (l *LogLimiter) Dropped() int64 {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return l.dropped
+}
+
+// Flush writes summaries for all pending suppressed occurrences and resets the limiter. Should be called on shutdown.
+func (l *LogLimiter) Flush() {
+	l.mutex.Lock()
+	now := l.now()
+	windows := l.windows
+	l.windows = make(map[string]*limitWindow)
+	l.mutex.Unlock()
+
+	for _, w := range windows {
+		if w.suppressed > 0 {
+			elapsed := now.Sub(w.start)
+			if elapsed > l.interval {
+				elapsed = l.interval
+			}
+			w.log(elapsed)
+		}
+	}
+}
+
+// log writes the summary of suppressed occurrences as SuppressedError to LogSink or as line to Logger.
+func (w *limitWindow) log(elapsed time.Duration) {
+	if LogSink != nil {
+		LogSink.Write(Record{SuppressedError.Make().Args(w.suppressed, w.errType, elapsed.Round(time.Second)), SeverityWarn, time.Now()})
+		return
+	}
+	logf(SeverityWarn, "[SUPPRESSED] suppressed %d occurrences of %s in last %v", w.suppressed, w.errType, elapsed.Round(time.Second))
+}
@@ -0,0 +1,168 @@
+package errors
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testClock struct {
+	mutex sync.Mutex
+	now   time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.now
+}
+
+func (c *testClock) Advance(d time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func setLimiter(t *testing.T, limiter *LogLimiter) {
+	old := Limiter
+	Limiter = limiter
+	t.Cleanup(func() { Limiter = old })
+}
+
+func TestLogLimiter(t *testing.T) {
+	clock := &testClock{now: time.Date(2024, 5, 2, 10, 0, 0, 0, time.UTC)}
+	limiter := NewLogLimiter(2, time.Minute).WithClock(clock.Now)
+	setLimiter(t, limiter)
+	lb := setLogBuffer()
+
+	template := New("Database unavailable")
+	for i := 0; i < 10; i++ {
+		template.Make().ToLog()
+		clock.Advance(time.Second)
+	}
+	New("other").Make().ToLog()
+	assert.Equal(t, 3, strings.Count(lb.String(), "[ERR "))
+	assert.Equal(t, int64(8), limiter.Dropped())
+	assert.NotContains(t, lb.String(), "[SUPPRESSED]")
+
+	clock.Advance(time.Minute)
+	template.Make().ToLog()
+	assert.Contains(t, lb.String(), "[SUPPRESSED] suppressed 8 occurrences of Database unavailable in last 1m0s")
+	assert.Equal(t, 4, strings.Count(lb.String(), "[ERR "))
+}
+
+func TestLogLimiterFlush(t *testing.T) {
+	clock := &testClock{now: time.Date(2024, 5, 2, 10, 0, 0, 0, time.UTC)}
+	limiter := NewLogLimiter(1, time.Minute).WithClock(clock.Now)
+	err := New("flush").Make()
+	assert.True(t, limiter.Allow(err))
+	assert.False(t, limiter.Allow(err))
+	assert.False(t, limiter.Allow(err))
+
+	lb := setLogBuffer()
+	clock.Advance(30 * time.Second)
+	limiter.Flush()
+	assert.Equal(t, "[SUPPRESSED] suppressed 2 occurrences of flush in last 30s", lb.String())
+	assert.True(t, limiter.Allow(err), "Flush should reset the limiter")
+}
+
+func TestLogLimiterSummaryOnOtherKey(t *testing.T) {
+	clock := &testClock{now: time.Date(2024, 5, 2, 10, 0, 0, 0, time.UTC)}
+	limiter := NewLogLimiter(1, time.Minute).WithClock(clock.Now)
+	for i := 0; i < 3; i++ {
+		limiter.Allow(New("burst").Make())
+	}
+	limiter.Allow(New("steady").Make())
+
+	lb := setLogBuffer()
+	clock.Advance(time.Minute)
+	assert.True(t, limiter.Allow(New("other").Make()))
+	assert.Equal(t, "[SUPPRESSED] suppressed 2 occurrences of burst in last 1m0s", lb.String())
+
+	limiter.mutex.Lock()
+	assert.Len(t, limiter.windows, 1, "Expired windows should be evicted")
+	limiter.mutex.Unlock()
+}
+
+func TestLogLimiterSummaryToLogSink(t *testing.T) {
+	clock := &testClock{now: time.Date(2024, 5, 2, 10, 0, 0, 0, time.UTC)}
+	setLimiter(t, NewLogLimiter(1, time.Minute).WithClock(clock.Now))
+	ring := NewRingBuffer(10)
+	LogSink = ring
+	defer func() { LogSink = nil }()
+	lb := setLogBuffer()
+
+	template := New("Database unavailable")
+	for i := 0; i < 3; i++ {
+		template.Make().ToLog()
+	}
+	clock.Advance(time.Minute)
+	New("other").Make().ToLog()
+
+	records := ring.Records()
+	if assert.Len(t, records, 3) {
+		assert.True(t, InstanceOf(records[0].Error, template))
+		assert.True(t, InstanceOf(records[1].Error, SuppressedError))
+		assert.Equal(t, "suppressed 2 occurrences of Database unavailable in last 1m0s", records[1].Error.Error())
+		assert.Equal(t, SeverityWarn, records[1].Severity)
+		assert.Equal(t, "other", records[2].Error.Error())
+	}
+	assert.Empty(t, lb.String(), "Summaries should not bypass LogSink")
+}
+
+func TestLogLimiterByFingerprint(t *testing.T) {
+	limiter := NewLogLimiter(1, time.Minute).ByFingerprint()
+	assert.True(t, limiter.Allow(loadTestError()))
+	assert.True(t, limiter.Allow(New("load").Trace().Make()), "Different call sites should be limited separately")
+	assert.False(t, limiter.Allow(loadTestError()))
+	assert.True(t, NewLogLimiter(1, time.Minute).Allow(loadTestError()))
+}
+
+func TestLogLimiterConcurrentByFingerprint(t *testing.T) {
+	limiter := NewLogLimiter(1, time.Hour)
+	err := New("switched").Make()
+
+	// run with -race to detect unsynchronized access to the key
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			limiter.Allow(err)
+		}()
+		go func() {
+			defer wg.Done()
+			limiter.ByFingerprint()
+		}()
+	}
+	wg.Wait()
+	// the first occurrence per key is allowed, which are at most type and fingerprint
+	dropped := limiter.Dropped()
+	assert.True(t, dropped == 8 || dropped == 9, "dropped %d", dropped)
+}
+
+func TestLogLimiterConcurrent(t *testing.T) {
+	limiter := NewLogLimiter(5, time.Hour)
+	err := New("concurrent").Make()
+
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	allowed := 0
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if limiter.Allow(err) {
+				mutex.Lock()
+				allowed++
+				mutex.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 5, allowed)
+	assert.Equal(t, int64(45), limiter.Dropped())
+}
@@ -0,0 +1,195 @@
+package errors
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var (
+	// Messages contains translated safe messages and is used to localize API responses.
+	Messages = NewCatalog()
+)
+
+// Catalog holds translated safe messages per locale keyed by error type or api error code. Translations use the same placeholders as the template message.
+type Catalog struct {
+	mutex sync.RWMutex
+	types map[string]map[ErrorType]string
+	codes map[string]map[int]string
+}
+
+// NewCatalog returns an empty message catalog.
+func NewCatalog() *Catalog {
+	return &Catalog{types: make(map[string]map[ErrorType]string), codes: make(map[string]map[int]string)}
+}
+
+// Add sets the translated message of an error type for the given locale.
+func (c *Catalog) Add(locale string, err TypedError, msg string) *Catalog {
+	return c.AddType(locale, err.GetType(), msg)
+}
+
+// AddType sets the translated message of an error type for the given locale.
+func (c *Catalog) AddType(locale string, errType ErrorType, msg string) *Catalog {
+	locale = normalizeLocale(locale)
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if _, ok := c.types[locale]; !ok {
+		c.types[locale] = make(map[ErrorType]string)
+	}
+	c.types[locale][errType] = msg
+	return c
+}
+
+// AddCode sets the translated message of an api error code for the given locale. Translations by error type take precedence.
+func (c *Catalog) AddCode(locale string, errCode int, msg string) *Catalog {
+	locale = normalizeLocale(locale)
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if _, ok := c.codes[locale]; !ok {
+		c.codes[locale] = make(map[int]string)
+	}
+	c.codes[locale][errCode] = msg
+	return c
+}
+
+// Lookup returns the translated message for an error type or api error code in the given locale. A regional locale like "de-CH" falls back to "de".
+func (c *Catalog) Lookup(locale string, errType ErrorType, errCode int) (string, bool) {
+	return c.lookup([]string{locale}, errType, errCode)
+}
+
+func (c *Catalog) lookup(locales []string, errType ErrorType, errCode int) (string, bool) {
+	if len(locales) == 0 {
+		return "", false
+	}
+
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	for _, locale := range locales {
+		locale = normalizeLocale(locale)
+		for _, candidate := range []string{locale, baseLocale(locale)} {
+			if msg, ok := c.types[candidate][errType]; ok {
+				return msg, true
+			}
+			if errCode != defaultErrCode {
+				if msg, ok := c.codes[candidate][errCode]; ok {
+					return msg, true
+				}
+			}
+		}
+	}
+	return "", false
+}
+
+// Locales returns all locales with at least one translation in alphabetical order.
+func (c *Catalog) Locales() []string {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	set := make(map[string]bool)
+	for locale := range c.types {
+		set[locale] = true
+	}
+	for locale := range c.codes {
+		set[locale] = true
+	}
+	locales := make([]string, 0, len(set))
+	for locale := range set {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+type catalogFile map[string]struct {
+	Types map[string]string `json:"types"`
+	Codes map[string]string `json:"codes"`
+}
+
+// LoadFile reads translations from a JSON file. See Load for the file format.
+func (c *Catalog) LoadFile(file string) Error {
+	f, err := os.Open(file)
+	if err != nil {
+		return CatalogError.Make().Args(file).Cause(err)
+	}
+	defer f.Close()
+	if err := c.Load(f); err != nil {
+		return CatalogError.Make().Args(file).Cause(err)
+	}
+	return nil
+}
+
+// Load reads translations in JSON format from r. The top level object maps locales to an object with "types" and "codes" translations:
+//
+//	{"de": {"types": {"File %s not found": "Datei %s nicht gefunden"}, "codes": {"12": "Ungültige Anfrage"}}}
+func (c *Catalog) Load(r io.Reader) Error {
+	var data catalogFile
+	if err := json.NewDecoder(r).Decode(&data); err != nil {
+		return CatalogFormatError.Make().Cause(err)
+	}
+
+	for locale, entries := range data {
+		for errType, msg := range entries.Types {
+			c.AddType(locale, ErrorType(errType), msg)
+		}
+		for code, msg := range entries.Codes {
+			errCode, err := strconv.Atoi(code)
+			if err != nil {
+				return CatalogFormatError.Make().StrCause("invalid error code %q for locale %q", code, locale)
+			}
+			c.AddCode(locale, errCode, msg)
+		}
+	}
+	return nil
+}
+
+// ParseAcceptLanguage returns the locales of an Accept-Language header ordered by preference. Wildcards and locales with zero quality are omitted.
+func ParseAcceptLanguage(header string) []string {
+	type weightedLocale struct {
+		locale  string
+		quality float64
+	}
+
+	var weighted []weightedLocale
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(part, ";")
+		locale := strings.TrimSpace(fields[0])
+		if locale == "" || locale == "*" {
+			continue
+		}
+
+		quality := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if q, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					quality = q
+				}
+			}
+		}
+		if quality <= 0 {
+			continue
+		}
+		weighted = append(weighted, weightedLocale{normalizeLocale(locale), quality})
+	}
+
+	sort.SliceStable(weighted, func(i, j int) bool { return weighted[i].quality > weighted[j].quality })
+	locales := make([]string, len(weighted))
+	for i := range weighted {
+		locales[i] = weighted[i].locale
+	}
+	return locales
+}
+
+func normalizeLocale(locale string) string {
+	return strings.ToLower(strings.Replace(strings.TrimSpace(locale), "_", "-", -1))
+}
+
+func baseLocale(locale string) string {
+	if i := strings.Index(locale, "-"); i > 0 {
+		return locale[:i]
+	}
+	return locale
+}
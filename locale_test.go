@@ -0,0 +1,132 @@
+package errors
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAcceptLanguage(t *testing.T) {
+	assert.Equal(t, []string{"de-ch", "fr", "en"}, ParseAcceptLanguage("fr;q=0.8, de-CH, *;q=0.5, en;q=0.1, it;q=0"))
+	assert.Equal(t, []string{}, ParseAcceptLanguage(""))
+}
+
+func TestLocalizedAPI(t *testing.T) {
+	defer resetMessages()
+	tmpl := New("File %s not found").API(404, 12)
+	Messages.Add("de", tmpl, "Datei %s nicht gefunden")
+
+	err := tmpl.Make().Args("foo.txt")
+	assert.Equal(t, "Datei foo.txt nicht gefunden", err.LocalizedAPI("de-DE").Message)
+	assert.Equal(t, "File foo.txt not found", err.LocalizedAPI("fr").Message)
+	assert.Equal(t, "File foo.txt not found", err.API().Message)
+}
+
+func TestLocalizedAPIByCode(t *testing.T) {
+	defer resetMessages()
+	Messages.AddCode("fr", 12, "Fichier %s introuvable")
+
+	err := New("File %s not found").API(404, 12).Make().Args("foo.txt")
+	assert.Equal(t, "Fichier foo.txt introuvable", err.LocalizedAPI("de", "fr").Message)
+}
+
+func TestLocalizedAPIKeepsCustomMessage(t *testing.T) {
+	defer resetMessages()
+	tmpl := New("File %s not found").API(404, 12)
+	Messages.Add("de", tmpl, "Datei %s nicht gefunden")
+
+	err := tmpl.Make().Msg("custom message").Safe()
+	assert.Equal(t, "custom message", err.LocalizedAPI("de").Message)
+}
+
+func TestLocalizedAPIKeepsTemplateMsg(t *testing.T) {
+	defer resetMessages()
+	tmpl := New("File %s not found").API(404, 12)
+	Messages.Add("de", tmpl, "Datei %s nicht gefunden")
+
+	err := tmpl.Msg("custom message").Make()
+	assert.Equal(t, "custom message", err.LocalizedAPI("de").Message)
+}
+
+func TestLocalizedDerivedMsg(t *testing.T) {
+	defer resetMessages()
+	parent := New("File not found").API(404, 12)
+	child := parent.Derive("ConfigNotFound").Msg("Config %s not found")
+	Messages.AddType("de", child.GetType(), "Konfiguration %s nicht gefunden")
+
+	assert.Equal(t, "Konfiguration app.yml nicht gefunden", child.Make().Args("app.yml").LocalizedAPI("de").Message)
+	assert.Equal(t, "custom", child.Msg("custom").Make().LocalizedAPI("de").Message, "Replacing the message of the derived template should disable localization")
+}
+
+func TestLocalizedAPIKeepsWrappedMessage(t *testing.T) {
+	defer resetMessages()
+	foreign := fmt.Errorf("foreign message")
+	Messages.AddType("de", getErrorType(foreign), "Fremder Fehler")
+
+	err := Wrap(foreign).Safe()
+	assert.True(t, strings.HasPrefix(err.LocalizedAPI("de").Message, "foreign message"))
+}
+
+func TestLocalizedAPIUnsafe(t *testing.T) {
+	defer resetMessages()
+	Messages.AddType("de", GenericSafeErrorType, "Ein Fehler ist aufgetreten")
+
+	err := New("secret").Untrack().Make()
+	assert.Equal(t, "Ein Fehler ist aufgetreten", err.LocalizedAPI("de").Message)
+}
+
+func TestToRequestAcceptLanguage(t *testing.T) {
+	defer resetMessages()
+	tmpl := New("File %s not found").API(404, 12)
+	Messages.Add("de", tmpl, "Datei %s nicht gefunden")
+
+	r := &headerRequestAborter{headers: map[string]string{"Accept-Language": "de-DE,de;q=0.9,en;q=0.8"}}
+	tmpl.Make().Args("foo.txt").ToRequest(r)
+	assert.Equal(t, API(404, 12, "Datei foo.txt nicht gefunden"), *r.lastError)
+}
+
+func TestCatalogLoadFile(t *testing.T) {
+	defer resetMessages()
+	dir, err := ioutil.TempDir("", "errors")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "messages.json")
+	assert.NoError(t, ioutil.WriteFile(file, []byte(`{"de": {"types": {"File %s not found": "Datei %s nicht gefunden"}, "codes": {"42": "Antwort"}}}`), 0600))
+	AssertNil(t, Messages.LoadFile(file))
+
+	msg, ok := Messages.Lookup("de", "File %s not found", 0)
+	assert.True(t, ok)
+	assert.Equal(t, "Datei %s nicht gefunden", msg)
+	msg, ok = Messages.Lookup("de-AT", "unknown", 42)
+	assert.True(t, ok)
+	assert.Equal(t, "Antwort", msg)
+	assert.Equal(t, []string{"de"}, Messages.Locales())
+}
+
+func TestCatalogLoadInvalid(t *testing.T) {
+	Assert(t, CatalogFormatError, NewCatalog().Load(strings.NewReader(`{"de": {"codes": {"abc": "foo"}}}`)))
+	Assert(t, CatalogError, NewCatalog().LoadFile("/this/file/does/not/exist.json"))
+}
+
+/* ############################################# */
+/* ###                Helper                 ### */
+/* ############################################# */
+
+type headerRequestAborter struct {
+	requestAborter
+	headers map[string]string
+}
+
+func (r *headerRequestAborter) GetHeader(key string) string {
+	return r.headers[key]
+}
+
+func resetMessages() {
+	Messages = NewCatalog()
+}
@@ -0,0 +1,102 @@
+package errors
+
+import (
+	"fmt"
+	"os"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CaptureMetadata enables recording of host name, process id and build info on instantiation of errors.
+var CaptureMetadata = false
+
+// Metadata describes the process and deployment an error occured in.
+type Metadata struct {
+	Host string `json:"host,omitempty"`
+	PID  int    `json:"pid,omitempty"`
+	// Module is the path of the main module.
+	Module string `json:"module,omitempty"`
+	// Version is the version of the main module, "(devel)" for local builds.
+	Version string `json:"version,omitempty"`
+	// Revision is the VCS revision the binary has been built from.
+	Revision string `json:"revision,omitempty"`
+	// RevisionTime is the commit time of Revision.
+	RevisionTime string `json:"revisionTime,omitempty"`
+	// Modified denotes uncommitted changes in the working tree on build.
+	Modified bool `json:"modified,omitempty"`
+}
+
+func (m Metadata) String() string {
+	var sb strings.Builder
+	writeValue := func(key, value string) {
+		if value != "" {
+			fmt.Fprintf(&sb, " %s=%s", key, value)
+		}
+	}
+	writeValue("host", m.Host)
+	if m.PID > 0 {
+		writeValue("pid", fmt.Sprint(m.PID))
+	}
+	writeValue("module", m.Module)
+	writeValue("version", m.Version)
+	writeValue("revision", m.Revision)
+	writeValue("revisionTime", m.RevisionTime)
+	if m.Modified {
+		writeValue("modified", "true")
+	}
+	return strings.TrimPrefix(sb.String(), " ")
+}
+
+var (
+	processMetadataOnce sync.Once
+	processMetadata     *Metadata
+)
+
+// currentMetadata returns the metadata of this process. It is only computed once.
+func currentMetadata() *Metadata {
+	processMetadataOnce.Do(func() {
+		var m Metadata
+		m.Host, _ = os.Hostname()
+		m.PID = os.Getpid()
+		if info, ok := debug.ReadBuildInfo(); ok {
+			m.Module = info.Main.Path
+			m.Version = info.Main.Version
+			for _, setting := range info.Settings {
+				switch setting.Key {
+				case "vcs.revision":
+					m.Revision = setting.Value
+				case "vcs.time":
+					m.RevisionTime = setting.Value
+				case "vcs.modified":
+					m.Modified = setting.Value == "true"
+				}
+			}
+		}
+		processMetadata = &m
+	})
+	return processMetadata
+}
+
+func (err baseError) CreatedAt() time.Time {
+	return err.trace.createdAt
+}
+
+func (err baseError) GetMetadata() (Metadata, bool) {
+	if err.trace.metadata == nil {
+		return Metadata{}, false
+	}
+	return *err.trace.metadata, true
+}
+
+// metaString returns the fingerprint, creation time and metadata of the error for log output. Only used if metadata has been captured.
+func (err baseError) metaString() string {
+	str := "fingerprint=" + err.Fingerprint() + " created=" + err.trace.createdAt.Format(time.RFC3339Nano)
+	if err.trace.metadata != nil {
+		if meta := err.trace.metadata.String(); meta != "" {
+			str += " " + meta
+		}
+	}
+	return str
+}
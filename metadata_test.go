@@ -0,0 +1,67 @@
+package errors
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreatedAt(t *testing.T) {
+	before := time.Now()
+	err := New("created").Make()
+	after := time.Now()
+
+	assert.False(t, err.CreatedAt().Before(before))
+	assert.False(t, err.CreatedAt().After(after))
+	assert.Equal(t, err.CreatedAt(), err.Msg("changed").Args().CreatedAt(), "Mutators should keep the creation time")
+}
+
+func TestMetadata(t *testing.T) {
+	_, ok := New("plain").Make().GetMetadata()
+	assert.False(t, ok)
+
+	CaptureMetadata = true
+	defer func() { CaptureMetadata = false }()
+
+	meta, ok := New("meta").Make().GetMetadata()
+	assert.True(t, ok)
+	host, _ := os.Hostname()
+	assert.Equal(t, host, meta.Host)
+	assert.Equal(t, os.Getpid(), meta.PID)
+	assert.Equal(t, currentMetadata(), currentMetadata(), "Metadata should only be computed once")
+}
+
+func TestMetadataString(t *testing.T) {
+	assert.Equal(t, "", Metadata{}.String())
+	assert.Equal(t, "host=web-1 pid=12 module=github.com/foo/app version=v1.2.0 revision=abc123 modified=true", Metadata{"web-1", 12, "github.com/foo/app", "v1.2.0", "abc123", "", true}.String())
+}
+
+func TestLogWithoutMetadata(t *testing.T) {
+	err := New("plain").Make()
+	lb := setLogBuffer()
+	err.ToLog()
+	assert.Equal(t, "[ERR "+err.GetID()+"] plain", lb.String(), "Log lines without metadata should not be changed")
+}
+
+func TestMetadataLog(t *testing.T) {
+	CaptureMetadata = true
+	defer func() { CaptureMetadata = false }()
+
+	err := New("meta").Make()
+	lb := setLogBuffer()
+	err.ToLog()
+	assert.Contains(t, lb.String(), "[ERR "+err.GetID()+"] meta | fingerprint="+err.Fingerprint()+" created="+err.CreatedAt().Format(time.RFC3339Nano)+" host=")
+
+	var result struct {
+		CreatedAt time.Time
+		Metadata  Metadata
+	}
+	data, jsonErr := json.Marshal(err.(baseError).toJSON(FormatOptions{}))
+	assert.NoError(t, jsonErr)
+	assert.NoError(t, json.Unmarshal(data, &result))
+	assert.True(t, err.CreatedAt().Equal(result.CreatedAt))
+	assert.Equal(t, os.Getpid(), result.Metadata.PID)
+}
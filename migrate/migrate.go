@@ -0,0 +1,388 @@
+// Package migrate rewrites call sites of fmt.Errorf, the standard errors package and github.com/pkg/errors to use templates and errors of this package.
+package migrate
+
+import (
+	"go/ast"
+	"go/build"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/sbreitf1/errors"
+	"github.com/sbreitf1/errors/internal/gosrc"
+	"github.com/sbreitf1/errors/internal/msgformat"
+)
+
+const (
+	// LibraryPath is the import path of the errors package.
+	LibraryPath = "github.com/sbreitf1/errors"
+	// PkgErrorsPath is the import path of the migrated github.com/pkg/errors package.
+	PkgErrorsPath = "github.com/pkg/errors"
+)
+
+var (
+	// ParseError denotes a package that could not be parsed.
+	ParseError = errors.NewTyped("migrate.ParseError", "Unable to parse package %q")
+	// FormatError denotes rewritten source code that could not be formatted.
+	FormatError = errors.NewTyped("migrate.FormatError", "Unable to format rewritten file %q")
+	// WriteError denotes a file that could not be written.
+	WriteError = errors.NewTyped("migrate.WriteError", "Unable to write file %q")
+)
+
+// Report describes a call site that could not be converted automatically.
+type Report struct {
+	// Source denotes the call site as "file:line".
+	Source  string
+	Message string
+}
+
+func (r Report) String() string {
+	return r.Source + ": " + r.Message
+}
+
+// Result contains the rewritten source code of a single file.
+type Result struct {
+	File     string
+	Original []byte
+	Source   []byte
+	Reports  []Report
+}
+
+// Changed returns true if the file has been modified.
+func (r Result) Changed() bool {
+	return string(r.Original) != string(r.Source)
+}
+
+// Diff returns the changes as unified diff.
+func (r Result) Diff() string {
+	return Diff(filepath.ToSlash(r.File), string(r.Original), string(r.Source))
+}
+
+// Write stores the rewritten source code if the file has been modified.
+func (r Result) Write() errors.Error {
+	if !r.Changed() {
+		return nil
+	}
+	info, err := os.Stat(r.File)
+	if err != nil {
+		return WriteError.Make().Args(r.File).Cause(err)
+	}
+	if err := ioutil.WriteFile(r.File, r.Source, info.Mode()); err != nil {
+		return WriteError.Make().Args(r.File).Cause(err)
+	}
+	return nil
+}
+
+// Packages rewrites all Go files (including tests) of the packages denoted by import paths or directories. Package level sentinels are converted to templates and references to them are updated in all given packages, so packages using sentinels of each other should be migrated together.
+func Packages(patterns []string) ([]Result, errors.Error) {
+	m := &migrator{fset: token.NewFileSet(), sentinels: make(map[string]map[string]bool), specs: make(map[*ast.ValueSpec]bool)}
+
+	var pkgs []*pkgFiles
+	for _, pattern := range patterns {
+		dir := pattern
+		if info, err := os.Stat(pattern); err != nil || !info.IsDir() {
+			wd, _ := os.Getwd()
+			pkg, err := build.Default.Import(pattern, wd, build.FindOnly)
+			if err != nil {
+				return nil, ParseError.Make().Args(pattern).Cause(err)
+			}
+			dir = pkg.Dir
+		}
+
+		parsed, err := parser.ParseDir(m.fset, dir, nil, parser.ParseComments)
+		if err != nil {
+			return nil, ParseError.Make().Args(pattern).Cause(err)
+		}
+		names := make([]string, 0, len(parsed))
+		for name := range parsed {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		path := gosrc.ImportPath(dir)
+		for _, name := range names {
+			pkgPath := path
+			if strings.HasSuffix(name, "_test") {
+				pkgPath += "_test"
+			}
+			pkg := &pkgFiles{dir, pkgPath, parsed[name]}
+			m.collectSentinels(pkg)
+			pkgs = append(pkgs, pkg)
+		}
+	}
+
+	var results []Result
+	for _, pkg := range pkgs {
+		files := make([]string, 0, len(pkg.pkg.Files))
+		for file := range pkg.pkg.Files {
+			files = append(files, file)
+		}
+		sort.Strings(files)
+
+		for _, file := range files {
+			result, err := m.rewrite(pkg, file, pkg.pkg.Files[file])
+			if err != nil {
+				return nil, err
+			}
+			results = append(results, result)
+		}
+	}
+	return results, nil
+}
+
+type pkgFiles struct {
+	dir  string
+	path string
+	pkg  *ast.Package
+}
+
+type migrator struct {
+	fset *token.FileSet
+	// sentinels contains the names of converted sentinels by import path.
+	sentinels map[string]map[string]bool
+	// specs contains all declarations of converted sentinels.
+	specs map[*ast.ValueSpec]bool
+}
+
+// imports contains the names of relevant packages imported by a file. Empty if not imported.
+type imports struct {
+	std, pkgErrors, fmt, lib string
+	// sentinels maps names of imported packages to their converted sentinels.
+	sentinels map[string]map[string]bool
+}
+
+func (m *migrator) fileImports(f *ast.File) imports {
+	imp := imports{sentinels: make(map[string]map[string]bool)}
+	for _, spec := range f.Imports {
+		path, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		name := ""
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		if name == "_" || name == "." {
+			continue
+		}
+		if name == "" {
+			name = defaultPackageName(path)
+		}
+		switch path {
+		case "errors":
+			imp.std = name
+		case PkgErrorsPath:
+			imp.pkgErrors = name
+		case "fmt":
+			imp.fmt = name
+		case LibraryPath:
+			imp.lib = name
+		default:
+			if names, ok := m.sentinels[path]; ok {
+				imp.sentinels[name] = names
+			}
+		}
+	}
+	return imp
+}
+
+// collectSentinels finds package level variables initialized using errors.New, pkg/errors.New or fmt.Errorf without arguments.
+func (m *migrator) collectSentinels(pkg *pkgFiles) {
+	names := make(map[string]bool)
+	for _, f := range pkg.pkg.Files {
+		imp := m.fileImports(f)
+		for _, decl := range f.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.VAR {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				vs := spec.(*ast.ValueSpec)
+				if vs.Type != nil || len(vs.Values) != len(vs.Names) {
+					continue
+				}
+				isSentinel := true
+				for _, value := range vs.Values {
+					if _, ok := sentinelMessage(imp, value); !ok {
+						isSentinel = false
+					}
+				}
+				if !isSentinel {
+					continue
+				}
+				m.specs[vs] = true
+				for _, name := range vs.Names {
+					names[name.Name] = true
+				}
+			}
+		}
+	}
+	if len(names) > 0 {
+		m.sentinels[pkg.path] = names
+	}
+}
+
+// sentinelMessage returns the message format of a sentinel declaration.
+func sentinelMessage(imp imports, expr ast.Expr) (string, bool) {
+	call, ok := expr.(*ast.CallExpr)
+	if !ok || len(call.Args) != 1 {
+		return "", false
+	}
+	lit, ok := call.Args[0].(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", false
+	}
+	msg, err := strconv.Unquote(lit.Value)
+	if err != nil {
+		return "", false
+	}
+	switch {
+	case isCall(call, imp.std, "New"), isCall(call, imp.pkgErrors, "New"):
+		return msg, true
+	case isCall(call, imp.fmt, "Errorf"):
+		if spec := msgformat.ParseErrorf(msg); spec.ArgCount > 0 || spec.Problem != "" {
+			return "", false
+		}
+		return unescapeFormat(msg), true
+	}
+	return "", false
+}
+
+// isCall returns true if the call invokes pkg.name() of an imported package.
+func isCall(call *ast.CallExpr, pkg string, names ...string) bool {
+	if pkg == "" {
+		return false
+	}
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+	id, ok := sel.X.(*ast.Ident)
+	if !ok || id.Name != pkg || id.Obj != nil {
+		return false
+	}
+	for _, name := range names {
+		if sel.Sel.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// unescapeFormat returns the message printed by a format string without verbs. Messages of templates are only formatted if args are given.
+func unescapeFormat(format string) string {
+	return strings.Replace(format, "%%", "%", -1)
+}
+
+// escapeFormat returns a format string printing msg. Used for Expand, which formats its message also without args.
+func escapeFormat(msg string) string {
+	return strings.Replace(msg, "%", "%%", -1)
+}
+
+// defaultPackageName guesses the package name from the last element of an import path.
+func defaultPackageName(path string) string {
+	name := path[strings.LastIndex(path, "/")+1:]
+	name = strings.TrimPrefix(name, "go-")
+	if i := strings.IndexAny(name, ".-"); i > 0 {
+		name = name[:i]
+	}
+	return name
+}
+
+// placeholder denotes the errors package in rewritten code until the import name is known.
+const placeholder = "__errorslib__"
+
+// finish adds and removes imports and replaces the placeholder of the errors package.
+func finish(file string, src []byte) ([]byte, errors.Error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, file, src, parser.ParseComments)
+	if err != nil {
+		return nil, FormatError.Make().Args(file).Cause(err)
+	}
+
+	used := make(map[string]bool)
+	ast.Inspect(f, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if id, ok := sel.X.(*ast.Ident); ok && id.Obj == nil {
+				used[id.Name] = true
+			}
+		}
+		return true
+	})
+
+	var edits []edit
+	names := make(map[string]bool)
+	libName := ""
+	var lastImport ast.Spec
+	var importDecl *ast.GenDecl
+	for _, decl := range f.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT {
+			continue
+		}
+		importDecl = gen
+		for _, spec := range gen.Specs {
+			is := spec.(*ast.ImportSpec)
+			lastImport = is
+			path, _ := strconv.Unquote(is.Path.Value)
+			name := defaultPackageName(path)
+			if is.Name != nil {
+				name = is.Name.Name
+			}
+			if path == LibraryPath {
+				libName = name
+			}
+			if (path == "errors" || path == PkgErrorsPath || path == "fmt") && !used[name] {
+				start, end := offset(fset, is.Pos()), offset(fset, is.End())
+				if len(gen.Specs) == 1 {
+					start, end = offset(fset, gen.Pos()), offset(fset, gen.End())
+				}
+				edits = append(edits, edit{start, end, ""})
+				continue
+			}
+			names[name] = true
+		}
+	}
+
+	if used[placeholder] && libName == "" {
+		libName = "errors"
+		if names[libName] {
+			libName = "liberrors"
+		}
+		spec := strconv.Quote(LibraryPath)
+		if libName != "errors" {
+			spec = libName + " " + spec
+		}
+		switch {
+		case importDecl == nil:
+			pos := offset(fset, f.Name.End())
+			edits = append(edits, edit{pos, pos, "\n\nimport " + spec})
+		case importDecl.Lparen.IsValid():
+			pos := offset(fset, lastImport.End())
+			edits = append(edits, edit{pos, pos, "\n\t" + spec})
+		default:
+			pos := offset(fset, importDecl.End())
+			edits = append(edits, edit{pos, pos, "\nimport " + spec})
+		}
+	}
+
+	src = applyEdits(src, edits)
+	if libName != "" {
+		src = []byte(strings.Replace(string(src), placeholder+".", libName+".", -1))
+	}
+	formatted, err := format.Source(src)
+	if err != nil {
+		return nil, FormatError.Make().Args(file).Cause(err)
+	}
+	return formatted, nil
+}
+
+func offset(fset *token.FileSet, pos token.Pos) int {
+	return fset.Position(pos).Offset
+}
@@ -0,0 +1,121 @@
+package migrate
+
+import (
+	goerrors "errors"
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sbreitf1/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+func TestPackages(t *testing.T) {
+	results, err := Packages([]string{"testdata/legacy", "testdata/legacy/client"})
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+
+	for _, result := range results {
+		golden := result.File + ".golden"
+		if *update {
+			require.NoError(t, ioutil.WriteFile(golden, result.Source, 0644))
+		}
+		expected, err := ioutil.ReadFile(golden)
+		require.NoError(t, err)
+		assert.Equal(t, string(expected), string(result.Source), golden)
+	}
+
+	assert.Equal(t, filepath.Join("testdata", "legacy", "legacy.go"), results[0].File)
+	var reports []string
+	for _, r := range results[0].Reports {
+		reports = append(reports, r.String())
+	}
+	assert.Equal(t, []string{
+		"legacy/legacy.go:20: sentinel with explicit type error not converted",
+		"legacy/legacy.go:49: usage of converted sentinel ErrLegacy not supported",
+		"legacy/legacy.go:53: fmt.Errorf with %w of possibly nil error not converted: add a nil check",
+		"legacy/legacy.go:55: pkgerrors.Wrapf of possibly nil error not converted: add a nil check",
+		"legacy/legacy.go:66: fmt.Errorf with %w not at the end of \"%w (retry)\" not converted",
+	}, reports)
+	assert.Empty(t, results[1].Reports)
+}
+
+func TestDiff(t *testing.T) {
+	assert.Equal(t, "", Diff("file.go", "a\nb\n", "a\nb\n"))
+
+	a := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n14\n15\n"
+	b := "1\n2\nthree\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n15\n16\n"
+	assert.Equal(t, `--- a/file.go
++++ b/file.go
+@@ -1,6 +1,6 @@
+ 1
+ 2
+-3
++three
+ 4
+ 5
+ 6
+@@ -11,5 +11,5 @@
+ 11
+ 12
+ 13
+-14
+ 15
++16
+`, Diff("file.go", a, b))
+
+	assert.Equal(t, `--- a/file.go
++++ b/file.go
+@@ -1,2 +1,2 @@
+ a
+-b
++c
+\ No newline at end of file
+`, Diff("file.go", "a\nb\n", "a\nc"))
+}
+
+func TestDefaultPackageName(t *testing.T) {
+	assert.Equal(t, "errors", defaultPackageName("github.com/pkg/errors"))
+	assert.Equal(t, "yaml", defaultPackageName("gopkg.in/yaml.v3"))
+	assert.Equal(t, "difflib", defaultPackageName("github.com/pmezard/go-difflib"))
+}
+
+func TestConvertedErrorfKeepsCause(t *testing.T) {
+	_, err := os.Open("does/not/exist")
+	var pathErr *os.PathError
+	// shapes generated for fmt.Errorf("open %s: %w", name, err) and fmt.Errorf("%w", err) with nil check
+	assert.True(t, goerrors.As(errors.Wrap(err).Expand("open %s", "file"), &pathErr))
+	assert.True(t, goerrors.Is(errors.Wrap(err), os.ErrNotExist))
+}
+
+func TestConvertedIsMatchesWrappedSentinel(t *testing.T) {
+	golden, err := ioutil.ReadFile(filepath.Join("testdata", "legacy", "legacy.go.golden"))
+	require.NoError(t, err)
+	// the converted functions Find, lookup and Missing of the golden file
+	for _, line := range []string{
+		`ErrNotFound = liberrors.New("resource not found")`,
+		`return liberrors.Wrap(err).Expand("find %s", name)`,
+		`return ErrNotFound.Make()`,
+		`return liberrors.InstanceOf(Find(name), ErrNotFound)`,
+	} {
+		require.Contains(t, string(golden), line)
+	}
+
+	ErrNotFound := errors.New("resource not found")
+	lookup := func(name string) error {
+		return ErrNotFound.Make()
+	}
+	find := func(name string) error {
+		if err := lookup(name); err != nil {
+			return errors.Wrap(err).Expand("find %s", name)
+		}
+		return nil
+	}
+	assert.True(t, errors.InstanceOf(find("config"), ErrNotFound), "Wrapped sentinels should keep their type")
+	assert.Equal(t, "find config: resource not found", find("config").Error(), "Messages should match fmt.Errorf")
+}
@@ -0,0 +1,392 @@
+package migrate
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/sbreitf1/errors"
+	"github.com/sbreitf1/errors/internal/gosrc"
+	"github.com/sbreitf1/errors/internal/msgformat"
+)
+
+// edit replaces the source code between two offsets.
+type edit struct {
+	start, end int
+	text       string
+}
+
+func applyEdits(src []byte, edits []edit) []byte {
+	sort.SliceStable(edits, func(i, j int) bool { return edits[i].start < edits[j].start })
+	var sb strings.Builder
+	last := 0
+	for _, e := range edits {
+		sb.Write(src[last:e.start])
+		sb.WriteString(e.text)
+		last = e.end
+	}
+	sb.Write(src[last:])
+	return []byte(sb.String())
+}
+
+// rewriter collects the edits of a single file. Nodes are visited in post-order, so inner expressions are rewritten before the enclosing call.
+type rewriter struct {
+	m       *migrator
+	pkg     *pkgFiles
+	src     []byte
+	imp     imports
+	edits   []edit
+	reports []Report
+}
+
+func (m *migrator) rewrite(pkg *pkgFiles, file string, f *ast.File) (Result, errors.Error) {
+	original, err := ioutil.ReadFile(file)
+	if err != nil {
+		return Result{}, ParseError.Make().Args(file).Cause(err)
+	}
+
+	r := &rewriter{m: m, pkg: pkg, src: original, imp: m.fileImports(f)}
+	if own, ok := m.sentinels[pkg.path]; ok {
+		r.imp.sentinels[""] = own
+	}
+
+	var stack []ast.Node
+	ast.Inspect(f, func(n ast.Node) bool {
+		if n != nil {
+			stack = append(stack, n)
+			return true
+		}
+		n, stack = stack[len(stack)-1], stack[:len(stack)-1]
+		r.visit(n, stack)
+		return true
+	})
+
+	result := Result{File: file, Original: original, Source: original, Reports: r.reports}
+	if len(r.edits) > 0 {
+		source, err := finish(file, applyEdits(original, r.edits))
+		if err != nil {
+			return Result{}, err
+		}
+		result.Source = source
+	}
+	return result, nil
+}
+
+func (r *rewriter) offset(pos token.Pos) int {
+	return offset(r.m.fset, pos)
+}
+
+// text returns the source code of a node including all edits inside of it.
+func (r *rewriter) text(n ast.Node) string {
+	start, end := r.offset(n.Pos()), r.offset(n.End())
+	var inner []edit
+	for _, e := range r.edits {
+		if e.start >= start && e.end <= end {
+			inner = append(inner, edit{e.start - start, e.end - start, e.text})
+		}
+	}
+	return string(applyEdits(r.src[start:end], inner))
+}
+
+// replace replaces a node and all edits inside of it.
+func (r *rewriter) replace(n ast.Node, text string) {
+	start, end := r.offset(n.Pos()), r.offset(n.End())
+	edits := r.edits[:0]
+	for _, e := range r.edits {
+		if e.start < start || e.end > end {
+			edits = append(edits, e)
+		}
+	}
+	r.edits = append(edits, edit{start, end, text})
+}
+
+func (r *rewriter) report(n ast.Node, msg string, args ...interface{}) {
+	r.reports = append(r.reports, Report{gosrc.Position(r.m.fset, n.Pos(), r.pkg.dir), fmt.Sprintf(msg, args...)})
+}
+
+func (r *rewriter) visit(n ast.Node, parents []ast.Node) {
+	switch n := n.(type) {
+	case *ast.ValueSpec:
+		if r.m.specs[n] {
+			r.convertSentinel(n)
+		} else if n.Type != nil && len(n.Values) > 0 && !inFunction(parents) {
+			for _, value := range n.Values {
+				if _, ok := sentinelMessage(r.imp, value); !ok {
+					return
+				}
+			}
+			r.report(n, "sentinel with explicit type %s not converted", r.text(n.Type))
+		}
+	case *ast.Ident:
+		if r.isSentinel(n, parents) {
+			r.useSentinel(n, parents)
+		}
+	case *ast.SelectorExpr:
+		if r.isSentinel(n, parents) {
+			r.useSentinel(n, parents)
+		}
+	case *ast.BinaryExpr:
+		if n.Op != token.EQL && n.Op != token.NEQ {
+			return
+		}
+		x, y := n.X, n.Y
+		if r.isSentinel(x, nil) {
+			x, y = y, x
+		}
+		if !r.isSentinel(y, nil) {
+			return
+		}
+		not := ""
+		if n.Op == token.NEQ {
+			not = "!"
+		}
+		r.replace(n, fmt.Sprintf("%s%s.InstanceOf(%s, %s)", not, placeholder, r.text(x), r.text(y)))
+	case *ast.CallExpr:
+		if !inFunction(parents) {
+			return
+		}
+		if isCall(n, r.imp.std, "Is") || isCall(n, r.imp.pkgErrors, "Is") {
+			if len(n.Args) == 2 && r.isSentinel(n.Args[1], nil) {
+				r.replace(n, fmt.Sprintf("%s.InstanceOf(%s, %s)", placeholder, r.text(n.Args[0]), r.text(n.Args[1])))
+				return
+			}
+		}
+		if isCall(n, r.imp.fmt, "Errorf") {
+			r.convertErrorf(n, parents)
+		} else if name, ok := pkgFunc(n, r.imp.pkgErrors); ok {
+			r.convertPkgErrors(n, name, parents)
+		}
+	}
+}
+
+// isSentinel returns true if the expression references a converted sentinel. Parents are only used to exclude declarations and must be nil for operands.
+func (r *rewriter) isSentinel(expr ast.Expr, parents []ast.Node) bool {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		if !r.imp.sentinels[""][e.Name] {
+			return false
+		}
+		if e.Obj != nil {
+			vs, ok := e.Obj.Decl.(*ast.ValueSpec)
+			if !ok || !r.m.specs[vs] {
+				return false
+			}
+		}
+		if len(parents) > 0 {
+			switch p := parents[len(parents)-1].(type) {
+			case *ast.ValueSpec:
+				for _, name := range p.Names {
+					if name == e {
+						return false
+					}
+				}
+			case *ast.SelectorExpr:
+				return p.Sel != e
+			case *ast.KeyValueExpr:
+				return p.Key != e
+			case *ast.Field, *ast.LabeledStmt, *ast.BranchStmt:
+				return false
+			}
+		}
+		return true
+	case *ast.SelectorExpr:
+		id, ok := e.X.(*ast.Ident)
+		return ok && id.Obj == nil && r.imp.sentinels[id.Name] != nil && r.imp.sentinels[id.Name][e.Sel.Name]
+	}
+	return false
+}
+
+// useSentinel instantiates the converted template at places where an error value is expected.
+func (r *rewriter) useSentinel(expr ast.Expr, parents []ast.Node) {
+	switch p := parents[len(parents)-1].(type) {
+	case *ast.BinaryExpr:
+		if p.Op == token.EQL || p.Op == token.NEQ {
+			return
+		}
+	case *ast.CallExpr:
+		if (isCall(p, r.imp.std, "Is") || isCall(p, r.imp.pkgErrors, "Is")) && len(p.Args) == 2 && p.Args[1] == expr {
+			return
+		}
+		if p.Fun == expr {
+			break
+		}
+		r.replace(expr, r.text(expr)+".Make()")
+		return
+	case *ast.ReturnStmt, *ast.AssignStmt, *ast.ValueSpec, *ast.CompositeLit, *ast.KeyValueExpr, *ast.SendStmt:
+		r.replace(expr, r.text(expr)+".Make()")
+		return
+	case *ast.SelectorExpr:
+		if p.X == expr {
+			r.replace(expr, r.text(expr)+".Make()")
+			return
+		}
+	}
+	r.report(expr, "usage of converted sentinel %s not supported", r.text(expr))
+}
+
+// convertSentinel replaces the initialization of a package level sentinel by a template.
+func (r *rewriter) convertSentinel(vs *ast.ValueSpec) {
+	for _, value := range vs.Values {
+		msg, _ := sentinelMessage(r.imp, value)
+		r.replace(value, fmt.Sprintf("%s.New(%s)", placeholder, strconv.Quote(msg)))
+	}
+}
+
+// convertErrorf replaces fmt.Errorf("msg: %w", args..., err) by errors.Wrap(err).Expand("msg", args...), which keeps the type of err for InstanceOf and leaves it reachable for errors.Is and errors.As. Calls are only converted if err is known to be non-nil, because Wrap returns nil for nil errors.
+func (r *rewriter) convertErrorf(call *ast.CallExpr, parents []ast.Node) {
+	if len(call.Args) == 0 {
+		return
+	}
+	format, ok := stringLiteral(call.Args[0])
+	if !ok || !strings.Contains(format, "%w") {
+		return
+	}
+
+	runes, ok := msgformat.ParseErrorf(format).Simple()
+	verbs := string(runes)
+	if !ok || strings.Count(verbs, "w") != 1 || strings.Index(verbs, "w") != len(verbs)-1 || len(call.Args) != len(verbs)+1 {
+		r.report(call, "fmt.Errorf with unsupported format %q not converted", format)
+		return
+	}
+	var prefix string
+	switch {
+	case format == "%w":
+	case strings.HasSuffix(format, ": %w"):
+		prefix = strings.TrimSuffix(format, ": %w")
+	default:
+		r.report(call, "fmt.Errorf with %%w not at the end of %q not converted", format)
+		return
+	}
+
+	wrapped := call.Args[len(call.Args)-1]
+	if !isGuarded(wrapped, parents) {
+		r.report(call, "fmt.Errorf with %%w of possibly nil error not converted: add a nil check")
+		return
+	}
+	if prefix == "" {
+		r.replace(call, fmt.Sprintf("%s.Wrap(%s)", placeholder, r.text(wrapped)))
+		return
+	}
+	args := []string{strconv.Quote(prefix)}
+	for _, arg := range call.Args[1 : len(call.Args)-1] {
+		args = append(args, r.text(arg))
+	}
+	r.replace(call, fmt.Sprintf("%s.Wrap(%s).Expand(%s)", placeholder, r.text(wrapped), strings.Join(args, ", ")))
+}
+
+// convertPkgErrors replaces calls to github.com/pkg/errors. Wrap functions are only converted if the wrapped error is known to be non-nil, because they return nil for nil errors.
+func (r *rewriter) convertPkgErrors(call *ast.CallExpr, name string, parents []ast.Node) {
+	var args []string
+	for _, arg := range call.Args {
+		args = append(args, r.text(arg))
+	}
+
+	switch name {
+	case "New":
+		if len(call.Args) != 1 {
+			return
+		}
+		if msg, ok := stringLiteral(call.Args[0]); ok {
+			r.replace(call, fmt.Sprintf("%s.GenericError.Msg(%s).Make()", placeholder, strconv.Quote(msg)))
+		} else {
+			r.replace(call, fmt.Sprintf("%s.GenericError.Msg(\"%%s\", %s).Make()", placeholder, args[0]))
+		}
+
+	case "Errorf":
+		if len(call.Args) == 1 {
+			if format, ok := stringLiteral(call.Args[0]); ok {
+				args[0] = strconv.Quote(unescapeFormat(format))
+			}
+		}
+		r.replace(call, fmt.Sprintf("%s.GenericError.Msg(%s).Make()", placeholder, strings.Join(args, ", ")))
+
+	case "WithStack":
+		if len(call.Args) == 1 {
+			r.replace(call, fmt.Sprintf("%s.Wrap(%s)", placeholder, args[0]))
+		}
+
+	case "Wrap", "Wrapf", "WithMessage", "WithMessagef":
+		if len(call.Args) < 2 {
+			return
+		}
+		if !isGuarded(call.Args[0], parents) {
+			r.report(call, "%s.%s of possibly nil error not converted: add a nil check", r.imp.pkgErrors, name)
+			return
+		}
+		msgArgs := args[1:]
+		if name == "Wrap" || name == "WithMessage" {
+			if msg, ok := stringLiteral(call.Args[1]); ok {
+				msgArgs = []string{strconv.Quote(escapeFormat(msg))}
+			} else {
+				msgArgs = []string{"\"%s\"", args[1]}
+			}
+		}
+		r.replace(call, fmt.Sprintf("%s.Wrap(%s).Expand(%s)", placeholder, args[0], strings.Join(msgArgs, ", ")))
+
+	default:
+		r.report(call, "%s.%s not converted", r.imp.pkgErrors, name)
+	}
+}
+
+// isGuarded returns true if expr is a variable and the node is located in the body of an if statement checking the variable for nil.
+func isGuarded(expr ast.Expr, parents []ast.Node) bool {
+	id, ok := expr.(*ast.Ident)
+	if !ok {
+		return false
+	}
+	for i := len(parents) - 2; i >= 0; i-- {
+		switch p := parents[i].(type) {
+		case *ast.FuncDecl, *ast.FuncLit:
+			return false
+		case *ast.IfStmt:
+			if p.Body != parents[i+1] {
+				continue
+			}
+			cond, ok := p.Cond.(*ast.BinaryExpr)
+			if !ok || cond.Op != token.NEQ {
+				continue
+			}
+			if (isIdent(cond.X, id.Name) && isIdent(cond.Y, "nil")) || (isIdent(cond.X, "nil") && isIdent(cond.Y, id.Name)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// pkgFunc returns the name of the called function if the call invokes a function of the imported package pkg.
+func pkgFunc(call *ast.CallExpr, pkg string) (string, bool) {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || !isCall(call, pkg, sel.Sel.Name) {
+		return "", false
+	}
+	return sel.Sel.Name, true
+}
+
+func isIdent(expr ast.Expr, name string) bool {
+	id, ok := expr.(*ast.Ident)
+	return ok && id.Name == name
+}
+
+func inFunction(parents []ast.Node) bool {
+	for _, p := range parents {
+		switch p.(type) {
+		case *ast.FuncDecl, *ast.FuncLit:
+			return true
+		}
+	}
+	return false
+}
+
+func stringLiteral(expr ast.Expr) (string, bool) {
+	lit, ok := expr.(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", false
+	}
+	str, err := strconv.Unquote(lit.Value)
+	return str, err == nil
+}
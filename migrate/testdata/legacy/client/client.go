@@ -0,0 +1,15 @@
+package client
+
+import (
+	"errors"
+
+	"github.com/sbreitf1/errors/migrate/testdata/legacy"
+)
+
+func Get(name string) error {
+	_, err := legacy.Load(name)
+	if errors.Is(err, legacy.ErrNotFound) {
+		return legacy.ErrNotFound
+	}
+	return err
+}
@@ -0,0 +1,96 @@
+package legacy
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	pkgerrors "github.com/pkg/errors"
+)
+
+var (
+	// ErrNotFound denotes a missing resource.
+	ErrNotFound = errors.New("resource not found")
+	// ErrQuota denotes an exceeded quota.
+	ErrQuota  = fmt.Errorf("quota of 100%% exceeded")
+	ErrLegacy = pkgerrors.New("legacy 50% failure")
+)
+
+var ErrTyped error = errors.New("typed sentinel")
+
+func Load(name string) ([]byte, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("open %s: %w", name, err)
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil, pkgerrors.Wrap(err, "read")
+	}
+	if len(data) == 0 {
+		return nil, ErrNotFound
+	}
+	return data, nil
+}
+
+func Check(err error) bool {
+	if errors.Is(err, ErrNotFound) || err == ErrQuota {
+		return true
+	}
+	var pathErr *os.PathError
+	return errors.As(err, &pathErr)
+}
+
+func Process(err error) error {
+	switch err {
+	case ErrLegacy:
+		return nil
+	}
+	if err != ErrLegacy {
+		return fmt.Errorf("process: %w", err)
+	}
+	return pkgerrors.Wrapf(err, "process %d", 1)
+}
+
+func Failures() error {
+	if err := run(); err != nil {
+		return pkgerrors.WithMessage(err, "run failed")
+	}
+	return pkgerrors.Errorf("%d failures", 2)
+}
+
+func run() error {
+	return fmt.Errorf("%w (retry)", ErrQuota)
+}
+
+func Limit(err error) error {
+	if err != nil {
+		return fmt.Errorf("limit of 100%%: %w", err)
+	}
+	return pkgerrors.Errorf("at 100%%")
+}
+
+func Find(name string) error {
+	if err := lookup(name); err != nil {
+		return fmt.Errorf("find %s: %w", name, err)
+	}
+	return nil
+}
+
+func lookup(name string) error {
+	return ErrNotFound
+}
+
+func Missing(name string) bool {
+	return errors.Is(Find(name), ErrNotFound)
+}
+
+func Progress(err error) error {
+	if err != nil {
+		return pkgerrors.Wrap(err, "at 50% progress")
+	}
+	return nil
+}
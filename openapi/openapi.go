@@ -0,0 +1,195 @@
+// Package openapi generates OpenAPI 3 components describing the error responses of error templates.
+package openapi
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/sbreitf1/errors"
+	"github.com/sbreitf1/errors/catalog"
+)
+
+var (
+	// WriteError denotes a document that could not be written.
+	WriteError = errors.NewTyped("openapi.WriteError", "Unable to write OpenAPI components")
+)
+
+const (
+	// APIErrorSchema is the name of the schema component for errors.APIError.
+	APIErrorSchema = "APIError"
+	// ProblemDetailsSchema is the name of the schema component for RFC 7807 problem details.
+	ProblemDetailsSchema = "ProblemDetails"
+)
+
+// Options control the generated components.
+type Options struct {
+	// ProblemDetails generates RFC 7807 problem details (application/problem+json) instead of the errors.APIError schema.
+	ProblemDetails bool
+	// ResponsePrefix is prepended to the status code in the name of response components. Defaults to "Error".
+	ResponsePrefix string
+}
+
+// Document is an OpenAPI document only containing components.
+type Document struct {
+	Components Components `json:"components"`
+}
+
+// Components contains reusable schemas and responses.
+type Components struct {
+	Schemas   map[string]*Schema   `json:"schemas"`
+	Responses map[string]*Response `json:"responses"`
+}
+
+// Schema is a subset of the OpenAPI schema object.
+type Schema struct {
+	Ref         string             `json:"$ref,omitempty"`
+	Type        string             `json:"type,omitempty"`
+	Format      string             `json:"format,omitempty"`
+	Description string             `json:"description,omitempty"`
+	Required    []string           `json:"required,omitempty"`
+	Properties  map[string]*Schema `json:"properties,omitempty"`
+	Enum        []interface{}      `json:"enum,omitempty"`
+	AllOf       []*Schema          `json:"allOf,omitempty"`
+}
+
+// Response is an OpenAPI response object.
+type Response struct {
+	Description string                `json:"description"`
+	Content     map[string]*MediaType `json:"content"`
+}
+
+// MediaType is an OpenAPI media type object.
+type MediaType struct {
+	Schema   *Schema             `json:"schema"`
+	Examples map[string]*Example `json:"examples,omitempty"`
+}
+
+// Example is an OpenAPI example object.
+type Example struct {
+	Summary string      `json:"summary,omitempty"`
+	Value   interface{} `json:"value"`
+}
+
+// ResponseName returns the name of the response component for a HTTP status code.
+func (opts Options) ResponseName(httpCode int) string {
+	prefix := opts.ResponsePrefix
+	if prefix == "" {
+		prefix = "Error"
+	}
+	return prefix + strconv.Itoa(httpCode)
+}
+
+// FromTemplates generates components for the given templates, e.g. all templates of errors.DefaultRegistry.
+func FromTemplates(templates []errors.Template, opts Options) Document {
+	return Generate(catalog.FromTemplates(templates), opts)
+}
+
+// Generate returns components with an error schema and a response object per HTTP status code. Every response lists the possible error codes together with their safe messages as examples.
+func Generate(entries []catalog.Entry, opts Options) Document {
+	doc := Document{Components{Schemas: make(map[string]*Schema), Responses: make(map[string]*Response)}}
+
+	schemaName, mediaType := APIErrorSchema, "application/json"
+	if opts.ProblemDetails {
+		schemaName, mediaType = ProblemDetailsSchema, "application/problem+json"
+		doc.Components.Schemas[schemaName] = problemDetailsSchema()
+	} else {
+		doc.Components.Schemas[schemaName] = apiErrorSchema()
+	}
+
+	byStatus := make(map[int][]catalog.Entry)
+	for _, e := range entries {
+		byStatus[e.HTTPCode] = append(byStatus[e.HTTPCode], e)
+	}
+
+	for status, statusEntries := range byStatus {
+		catalog.Sort(statusEntries)
+
+		var codes []interface{}
+		knownCodes := make(map[int]bool)
+		examples := make(map[string]*Example)
+		for _, e := range statusEntries {
+			if !knownCodes[e.ErrCode] {
+				knownCodes[e.ErrCode] = true
+				codes = append(codes, e.ErrCode)
+			}
+			examples[exampleName(e)] = &Example{Summary: e.Type, Value: exampleValue(e, status, opts)}
+		}
+
+		description := http.StatusText(status)
+		if description == "" {
+			description = "HTTP " + strconv.Itoa(status)
+		}
+		doc.Components.Responses[opts.ResponseName(status)] = &Response{
+			Description: description,
+			Content: map[string]*MediaType{
+				mediaType: {
+					Schema: &Schema{AllOf: []*Schema{
+						{Ref: "#/components/schemas/" + schemaName},
+						{Properties: map[string]*Schema{"code": {Enum: codes}}},
+					}},
+					Examples: examples,
+				},
+			},
+		}
+	}
+	return doc
+}
+
+// WriteJSON writes the document as indented JSON.
+func (doc Document) WriteJSON(w io.Writer) errors.Error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return WriteError.Make().Cause(err)
+	}
+	return nil
+}
+
+func apiErrorSchema() *Schema {
+	return &Schema{
+		Type:        "object",
+		Description: "Error response with api error code and safe message.",
+		Required:    []string{"code", "message"},
+		Properties: map[string]*Schema{
+			"code":    {Type: "integer", Format: "int32", Description: "Api error code"},
+			"message": {Type: "string", Description: "Safe error message"},
+		},
+	}
+}
+
+func problemDetailsSchema() *Schema {
+	return &Schema{
+		Type:        "object",
+		Description: "Problem details as specified by RFC 7807 with api error code extension.",
+		Required:    []string{"type", "title", "status", "code"},
+		Properties: map[string]*Schema{
+			"type":     {Type: "string", Format: "uri-reference", Description: "Error type"},
+			"title":    {Type: "string", Description: "Safe error message"},
+			"status":   {Type: "integer", Format: "int32", Description: "HTTP status code"},
+			"detail":   {Type: "string", Description: "Occurrence specific explanation"},
+			"instance": {Type: "string", Format: "uri-reference", Description: "Occurrence specific reference"},
+			"code":     {Type: "integer", Format: "int32", Description: "Api error code"},
+		},
+	}
+}
+
+// exampleName returns a unique name for the example of an entry. Variable names are qualified by the package, because they are only unique within their package.
+func exampleName(e catalog.Entry) string {
+	if e.Name != "" && e.Package != "" {
+		return e.Package + "." + e.Name
+	}
+	return e.Type
+}
+
+func exampleValue(e catalog.Entry, status int, opts Options) interface{} {
+	message := errors.GenericSafeErrorMessage
+	if e.Safe {
+		message = e.Message
+	}
+	if opts.ProblemDetails {
+		return map[string]interface{}{"type": e.Type, "title": message, "status": status, "code": e.ErrCode}
+	}
+	return errors.API(status, e.ErrCode, message)
+}
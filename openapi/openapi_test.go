@@ -0,0 +1,65 @@
+package openapi
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/sbreitf1/errors"
+	"github.com/sbreitf1/errors/catalog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromTemplates(t *testing.T) {
+	notFound := errors.NewTyped("storage.NotFound", "Resource %s not found").API(404, 10)
+	templates := []errors.Template{
+		notFound.Derive("storage.UserNotFound").ErrCode(11),
+		notFound,
+		errors.NewTyped("storage.Internal", "Secret database error"),
+	}
+	doc := FromTemplates(templates, Options{})
+
+	assert.Contains(t, doc.Components.Schemas, APIErrorSchema)
+	assert.Len(t, doc.Components.Responses, 2)
+
+	notFoundResponse := doc.Components.Responses["Error404"]
+	assert.Equal(t, "Not Found", notFoundResponse.Description)
+	media := notFoundResponse.Content["application/json"]
+	assert.Equal(t, "#/components/schemas/APIError", media.Schema.AllOf[0].Ref)
+	assert.Equal(t, []interface{}{10, 11}, media.Schema.AllOf[1].Properties["code"].Enum)
+	assert.Equal(t, errors.API(404, 11, "Resource %s not found"), media.Examples["storage.UserNotFound"].Value)
+
+	internalMedia := doc.Components.Responses["Error500"].Content["application/json"]
+	assert.Equal(t, errors.API(500, 0, errors.GenericSafeErrorMessage), internalMedia.Examples["storage.Internal"].Value)
+}
+
+func TestProblemDetails(t *testing.T) {
+	doc := FromTemplates([]errors.Template{errors.NewTyped("storage.NotFound", "Resource not found").API(404, 10)}, Options{ProblemDetails: true, ResponsePrefix: "Problem"})
+
+	assert.Contains(t, doc.Components.Schemas, ProblemDetailsSchema)
+	media := doc.Components.Responses["Problem404"].Content["application/problem+json"]
+	assert.Equal(t, "#/components/schemas/ProblemDetails", media.Schema.AllOf[0].Ref)
+	assert.Equal(t, map[string]interface{}{"type": "storage.NotFound", "title": "Resource not found", "status": 404, "code": 10}, media.Examples["storage.NotFound"].Value)
+}
+
+func TestGenerateExampleNames(t *testing.T) {
+	doc := Generate([]catalog.Entry{
+		{Name: "NotFound", Package: "example.com/users", Type: "users.NotFound", HTTPCode: 404, ErrCode: 10, Safe: true, Message: "User not found"},
+		{Name: "NotFound", Package: "example.com/orders", Type: "orders.NotFound", HTTPCode: 404, ErrCode: 20, Safe: true, Message: "Order not found"},
+	}, Options{})
+
+	examples := doc.Components.Responses["Error404"].Content["application/json"].Examples
+	assert.Len(t, examples, 2)
+	assert.Equal(t, errors.API(404, 10, "User not found"), examples["example.com/users.NotFound"].Value)
+	assert.Equal(t, errors.API(404, 20, "Order not found"), examples["example.com/orders.NotFound"].Value)
+}
+
+func TestWriteJSON(t *testing.T) {
+	doc := FromTemplates([]errors.Template{errors.NewTyped("storage.NotFound", "Resource not found").API(404, 10)}, Options{})
+	var sb strings.Builder
+	errors.AssertNil(t, doc.WriteJSON(&sb))
+
+	var data map[string]interface{}
+	assert.NoError(t, json.Unmarshal([]byte(sb.String()), &data))
+	assert.True(t, strings.Contains(sb.String(), `"$ref": "#/components/schemas/APIError"`))
+}
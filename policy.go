@@ -0,0 +1,117 @@
+package errors
+
+// Decision is the result of a LogRule.
+type Decision int
+
+const (
+	// Abstain leaves the decision to the next rule.
+	Abstain Decision = iota
+	// Log writes the error to log, even if it is untracked.
+	Log
+	// Skip suppresses logging of the error.
+	Skip
+)
+
+func (d Decision) String() string {
+	switch d {
+	case Log:
+		return "log"
+	case Skip:
+		return "skip"
+	default:
+		return "abstain"
+	}
+}
+
+// LogRule decides whether an error should be written to log by ToLog().
+type LogRule func(err Error) Decision
+
+// LogPolicy is an ordered list of rules. The first rule that does not abstain decides.
+type LogPolicy []LogRule
+
+// Policy is evaluated by ToLog() and ToRequestAndLog() before an error is written to log. ForceLog() ignores the policy. Errors are logged if tracked when all rules abstain.
+var Policy LogPolicy
+
+// Decide returns the decision of the first rule that does not abstain. Can be used as LogRule to nest policies.
+func (p LogPolicy) Decide(err Error) Decision {
+	for _, rule := range p {
+		if d := rule(err); d != Abstain {
+			return d
+		}
+	}
+	return Abstain
+}
+
+// SkipHTTPCodesBelow skips errors with HTTP codes less than code, e.g. 500 to only log server errors.
+func SkipHTTPCodesBelow(code int) LogRule {
+	return func(err Error) Decision {
+		if err.API().ResponseCode < code {
+			return Skip
+		}
+		return Abstain
+	}
+}
+
+// SkipTypes skips errors of the given types and all derived types.
+func SkipTypes(types ...TypedError) LogRule {
+	return func(err Error) Decision {
+		for _, t := range types {
+			if err.IsKindOf(t) {
+				return Skip
+			}
+		}
+		return Abstain
+	}
+}
+
+// LogTypes logs errors of the given types and all derived types.
+func LogTypes(types ...TypedError) LogRule {
+	return func(err Error) Decision {
+		for _, t := range types {
+			if err.IsKindOf(t) {
+				return Log
+			}
+		}
+		return Abstain
+	}
+}
+
+// LogTagged logs errors with the given tag.
+func LogTagged(tag string) LogRule {
+	return func(err Error) Decision {
+		if err.IsTagged(tag) {
+			return Log
+		}
+		return Abstain
+	}
+}
+
+// OnlyTagged skips all errors without the given tag.
+func OnlyTagged(tag string) LogRule {
+	return func(err Error) Decision {
+		if !err.IsTagged(tag) {
+			return Skip
+		}
+		return Abstain
+	}
+}
+
+// LogTraced logs all errors with enabled stack trace.
+func LogTraced() LogRule {
+	return func(err Error) Decision {
+		if e, ok := err.(baseError); ok && e.flags.trace {
+			return Log
+		}
+		return Abstain
+	}
+}
+
+// When applies rule only to errors matching the condition.
+func When(condition func(err Error) bool, rule LogRule) LogRule {
+	return func(err Error) Decision {
+		if condition(err) {
+			return rule(err)
+		}
+		return Abstain
+	}
+}
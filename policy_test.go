@@ -0,0 +1,75 @@
+package errors
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func setPolicy(t *testing.T, rules ...LogRule) {
+	old := Policy
+	Policy = rules
+	t.Cleanup(func() { Policy = old })
+}
+
+func TestLogRules(t *testing.T) {
+	notFound := New("not found").HTTPCode(404)
+	userNotFound := notFound.Derive("user not found")
+	internal := New("internal")
+
+	assert.Equal(t, Skip, SkipHTTPCodesBelow(500)(notFound.Make()))
+	assert.Equal(t, Abstain, SkipHTTPCodesBelow(500)(internal.Make()))
+
+	assert.Equal(t, Skip, SkipTypes(internal, notFound)(userNotFound.Make()))
+	assert.Equal(t, Abstain, SkipTypes(internal)(userNotFound.Make()))
+	assert.Equal(t, Log, LogTypes(notFound)(userNotFound.Make()))
+	assert.Equal(t, Abstain, LogTypes(internal)(userNotFound.Make()))
+
+	audit := New("login failed").Tag("audit")
+	assert.Equal(t, Log, LogTagged("audit")(audit.Make()))
+	assert.Equal(t, Abstain, LogTagged("audit")(internal.Make()))
+	assert.Equal(t, Skip, OnlyTagged("audit")(internal.Make()))
+	assert.Equal(t, Abstain, OnlyTagged("audit")(audit.Make()))
+
+	assert.Equal(t, Log, LogTraced()(internal.Trace().Make()))
+	assert.Equal(t, Abstain, LogTraced()(internal.Make()))
+	assert.Equal(t, Abstain, LogTraced()(internal.Trace().Make().NoTrace()))
+
+	isInternal := func(err Error) bool { return err.Is(internal) }
+	assert.Equal(t, Skip, When(isInternal, OnlyTagged("audit"))(internal.Make()))
+	assert.Equal(t, Abstain, When(isInternal, OnlyTagged("audit"))(notFound.Make()))
+}
+
+func TestLogPolicyDecide(t *testing.T) {
+	policy := LogPolicy{LogTraced(), SkipHTTPCodesBelow(500)}
+	assert.Equal(t, Log, policy.Decide(New("traced").HTTPCode(400).Trace().Make()), "First matching rule should decide")
+	assert.Equal(t, Skip, policy.Decide(New("client").HTTPCode(400).Make()))
+	assert.Equal(t, Abstain, policy.Decide(New("server").Make()))
+	assert.Equal(t, Abstain, LogPolicy(nil).Decide(New("any").Make()))
+
+	nested := LogPolicy{OnlyTagged("audit"), policy.Decide}
+	assert.Equal(t, Skip, nested.Decide(New("traced").Trace().Make()))
+	assert.Equal(t, Log, nested.Decide(New("traced").Tag("audit").Trace().Make()))
+}
+
+func TestToLogPolicy(t *testing.T) {
+	setPolicy(t, SkipTypes(New("ignored")), LogTagged("audit"), SkipHTTPCodesBelow(500))
+
+	lb := setLogBuffer()
+	New("ignored").Make().ToLog()
+	New("client").HTTPCode(400).Make().ToLog()
+	assert.Equal(t, "", lb.String())
+
+	New("ignored").Make().ForceLog()
+	assert.Contains(t, lb.String(), "ignored", "ForceLog should ignore the policy")
+
+	lb = setLogBuffer()
+	New("login failed").API(401, 1).Tag("audit").Make().ToLog()
+	assert.Contains(t, lb.String(), "login failed", "Untracked errors should be logged on explicit decision")
+
+	lb = setLogBuffer()
+	New("untracked").Untrack().Make().ToLog()
+	assert.Equal(t, "", lb.String(), "Untracked errors should not be logged if all rules abstain")
+	New("internal").Make().ToRequestAndLog(&requestAborter{})
+	assert.Contains(t, lb.String(), "internal")
+}
@@ -0,0 +1,94 @@
+package errors
+
+import (
+	"sort"
+	"sync"
+)
+
+var (
+	// DefaultRegistry contains all templates registered using Register().
+	DefaultRegistry = NewRegistry()
+)
+
+// Registry keeps track of templates and allows for lookup by error type and api error code.
+type Registry struct {
+	mutex  sync.RWMutex
+	byType map[ErrorType]Template
+	byCode map[int]Template
+}
+
+// NewRegistry returns an empty template registry.
+func NewRegistry() *Registry {
+	return &Registry{byType: make(map[ErrorType]Template), byCode: make(map[int]Template)}
+}
+
+// Register adds this template to the DefaultRegistry and panics on duplicate error types or codes. Call this method at the end of the template definition.
+func (t Template) Register() Template {
+	return t.RegisterIn(DefaultRegistry)
+}
+
+// RegisterIn adds this template to the given registry and panics on duplicate error types or codes. Call this method at the end of the template definition.
+func (t Template) RegisterIn(r *Registry) Template {
+	if err := r.Add(t); err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// Add adds a template to the registry. Returns RegistryTypeError if the error type is already known and RegistryCodeError if the api error code is used by another template. Derived templates may share the error code of their ancestors, also with siblings deriving from the same ancestor.
+func (r *Registry) Add(t Template) Error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, ok := r.byType[t.errType]; ok {
+		return RegistryTypeError.Make().Args(t.errType)
+	}
+	if t.api.errCode != defaultErrCode {
+		if other, ok := r.byCode[t.api.errCode]; ok {
+			if other.IsKindOf(t) {
+				// a registered template is derived from the new one -> use the more general one for lookup
+				r.byCode[t.api.errCode] = t
+			} else if !t.IsKindOf(other) && !sharesCode(t, other) {
+				return RegistryCodeError.Make().Args(t.api.errCode, t.errType, other.errType)
+			}
+		} else {
+			r.byCode[t.api.errCode] = t
+		}
+	}
+	r.byType[t.errType] = t
+	return nil
+}
+
+// sharesCode returns true if both templates inherited their error code from the same ancestor.
+func sharesCode(t, other Template) bool {
+	owner := t.api.codeOwner
+	return owner != "" && owner == other.api.codeOwner && isKindOf(t.errType, t.parents, owner) && isKindOf(other.errType, other.parents, owner)
+}
+
+// ByType returns the template of the given error type.
+func (r *Registry) ByType(errType ErrorType) (Template, bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	t, ok := r.byType[errType]
+	return t, ok
+}
+
+// ByCode returns the template of the given api error code. If derived templates share the same error code, the most general one is returned.
+func (r *Registry) ByCode(errCode int) (Template, bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	t, ok := r.byCode[errCode]
+	return t, ok
+}
+
+// Templates returns all registered templates ordered by error type.
+func (r *Registry) Templates() []Template {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	templates := make([]Template, 0, len(r.byType))
+	for _, t := range r.byType {
+		templates = append(templates, t)
+	}
+	sort.Slice(templates, func(i, j int) bool { return templates[i].errType < templates[j].errType })
+	return templates
+}
@@ -0,0 +1,88 @@
+package errors
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+	notFound := NewTyped("storage.NotFound", "Resource not found").API(404, 10).RegisterIn(r)
+	userNotFound := notFound.Derive("storage.UserNotFound").RegisterIn(r)
+	invalid := NewTyped("storage.Invalid", "Invalid request").API(400, 20).RegisterIn(r)
+	NewTyped("storage.Internal", "Internal error").RegisterIn(r)
+
+	tmpl, ok := r.ByType("storage.UserNotFound")
+	assert.True(t, ok)
+	assert.Equal(t, userNotFound.GetType(), tmpl.GetType())
+	_, ok = r.ByType("storage.Unknown")
+	assert.False(t, ok)
+
+	tmpl, ok = r.ByCode(10)
+	assert.True(t, ok)
+	assert.Equal(t, notFound.GetType(), tmpl.GetType())
+	tmpl, ok = r.ByCode(20)
+	assert.True(t, ok)
+	assert.Equal(t, invalid.GetType(), tmpl.GetType())
+	_, ok = r.ByCode(0)
+	assert.False(t, ok)
+
+	var types []ErrorType
+	for _, tmpl := range r.Templates() {
+		types = append(types, tmpl.GetType())
+	}
+	assert.Equal(t, []ErrorType{"storage.Internal", "storage.Invalid", "storage.NotFound", "storage.UserNotFound"}, types)
+}
+
+func TestRegistryDerivedFirst(t *testing.T) {
+	r := NewRegistry()
+	notFound := NewTyped("storage.NotFound", "Resource not found").API(404, 10)
+	AssertNil(t, r.Add(notFound.Derive("storage.UserNotFound")))
+	AssertNil(t, r.Add(notFound))
+
+	tmpl, ok := r.ByCode(10)
+	assert.True(t, ok)
+	assert.Equal(t, notFound.GetType(), tmpl.GetType())
+}
+
+func TestRegistrySiblings(t *testing.T) {
+	r := NewRegistry()
+	db := New("db").ErrCode(42)
+	AssertNil(t, r.Add(db.Derive("db.a")))
+	AssertNil(t, r.Add(db.Derive("db.b")))
+	assert.NotPanics(t, func() {
+		db.Derive("db.c").Derive("db.c.sub").RegisterIn(r)
+	})
+	AssertNil(t, r.Add(db))
+
+	tmpl, ok := r.ByCode(42)
+	assert.True(t, ok)
+	assert.Equal(t, db.GetType(), tmpl.GetType())
+
+	// siblings overriding the code of their parent do not share it
+	AssertNil(t, r.Add(db.Derive("db.d").ErrCode(7)))
+	Assert(t, RegistryCodeError, r.Add(db.Derive("db.e").ErrCode(7)))
+}
+
+func TestRegistryCollisions(t *testing.T) {
+	r := NewRegistry()
+	NewTyped("storage.NotFound", "Resource not found").API(404, 10).RegisterIn(r)
+
+	Assert(t, RegistryTypeError, r.Add(NewTyped("storage.NotFound", "Other message")))
+	Assert(t, RegistryCodeError, r.Add(NewTyped("storage.Invalid", "Invalid request").API(400, 10)))
+	assert.Panics(t, func() {
+		NewTyped("storage.Invalid", "Invalid request").API(400, 10).RegisterIn(r)
+	})
+	_, ok := r.ByType("storage.Invalid")
+	assert.False(t, ok)
+}
+
+func TestTemplateGetters(t *testing.T) {
+	tmpl := New("Resource %s not found").API(404, 10).TagStr("foo", "bar")
+	assert.Equal(t, "Resource %s not found", tmpl.GetMessage())
+	assert.Equal(t, 404, tmpl.GetHTTPCode())
+	assert.Equal(t, 10, tmpl.GetErrCode())
+	assert.True(t, tmpl.IsSafe())
+	assert.Equal(t, map[string]interface{}{"foo": "bar"}, tmpl.GetTags())
+}
@@ -0,0 +1,125 @@
+package errors
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Severity denotes the importance of an error for logging and alerting.
+type Severity int
+
+const (
+	// SeverityDefault denotes a severity that is derived from the HTTP code: 4xx errors are warnings and all other errors are errors.
+	SeverityDefault Severity = iota
+	// SeverityDebug denotes errors that are only relevant for debugging.
+	SeverityDebug
+	// SeverityInfo denotes expected errors like failed validation.
+	SeverityInfo
+	// SeverityWarn denotes errors caused by clients or recoverable problems.
+	SeverityWarn
+	// SeverityError denotes failed operations.
+	SeverityError
+	// SeverityCritical denotes errors that require immediate attention like data corruption.
+	SeverityCritical
+)
+
+var (
+	// MinSeverity is the minimum severity of errors written to log.
+	MinSeverity = SeverityDebug
+
+	// LevelLogger is called instead of Logger if set to pass the severity of errors to loggers with native levels.
+	LevelLogger func(severity Severity, msg string, args ...interface{})
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityDefault:
+		return "default"
+	case SeverityDebug:
+		return "debug"
+	case SeverityInfo:
+		return "info"
+	case SeverityWarn:
+		return "warn"
+	case SeverityError:
+		return "error"
+	case SeverityCritical:
+		return "critical"
+	default:
+		return fmt.Sprintf("Severity(%d)", int(s))
+	}
+}
+
+// MarshalText encodes the severity by name.
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText parses a severity name like "warn".
+func (s *Severity) UnmarshalText(text []byte) error {
+	for candidate := SeverityDefault; candidate <= SeverityCritical; candidate++ {
+		if strings.EqualFold(string(text), candidate.String()) {
+			*s = candidate
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown severity %q", string(text))
+}
+
+// logPrefix returns the prefix of log lines for errors of this severity.
+func (s Severity) logPrefix() string {
+	switch s {
+	case SeverityDebug:
+		return "DEBUG"
+	case SeverityInfo:
+		return "INFO"
+	case SeverityWarn:
+		return "WARN"
+	case SeverityCritical:
+		return "CRIT"
+	default:
+		return "ERR"
+	}
+}
+
+// severityOf returns the explicit severity or the default for the HTTP code.
+func severityOf(severity Severity, httpCode int) Severity {
+	if severity != SeverityDefault {
+		return severity
+	}
+	if httpCode >= 400 && httpCode < 500 {
+		return SeverityWarn
+	}
+	return SeverityError
+}
+
+// logf writes a log line with the given severity to LevelLogger or Logger.
+func logf(severity Severity, msg string, args ...interface{}) {
+	if LevelLogger != nil {
+		LevelLogger(severity, msg, args...)
+		return
+	}
+	Logger(msg, args...)
+}
+
+// Severity sets the severity of errors instantiated from this template.
+func (t Template) Severity(severity Severity) Template {
+	flags := t.flags
+	flags.severity = severity
+	return Template{t.errType, t.parents, t.content, flags, t.api}
+}
+
+// GetSeverity returns the severity of errors instantiated from this template.
+func (t Template) GetSeverity() Severity {
+	return severityOf(t.flags.severity, t.api.httpCode)
+}
+
+func (err baseError) Severity(severity Severity) Error {
+	flags := err.flags
+	flags.severity = severity
+	return baseError{err.errType, err.parents, err.content, flags, err.trace, err.api}
+}
+
+func (err baseError) GetSeverity() Severity {
+	return severityOf(err.flags.severity, err.api.httpCode)
+}
@@ -0,0 +1,85 @@
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSeverityDefaults(t *testing.T) {
+	assert.Equal(t, SeverityError, New("internal").GetSeverity())
+	assert.Equal(t, SeverityError, New("internal").Make().GetSeverity())
+	assert.Equal(t, SeverityWarn, New("not found").HTTPCode(404).Make().GetSeverity())
+	assert.Equal(t, SeverityWarn, New("bad request").API(400, 1).GetSeverity())
+	assert.Equal(t, SeverityError, New("unavailable").HTTPCode(503).Make().GetSeverity())
+}
+
+func TestSeverityOverride(t *testing.T) {
+	template := New("corrupted").Severity(SeverityCritical)
+	assert.Equal(t, SeverityCritical, template.GetSeverity())
+	assert.Equal(t, SeverityCritical, template.Derive("child").Make().GetSeverity(), "Severity should be inherited")
+	assert.Equal(t, SeverityCritical, template.HTTPCode(404).Make().GetSeverity(), "Explicit severity should not depend on HTTP code")
+
+	err := template.Make()
+	assert.Equal(t, SeverityInfo, err.Severity(SeverityInfo).GetSeverity())
+	assert.Equal(t, SeverityCritical, err.GetSeverity(), "Severity should return a copy")
+	assert.Equal(t, SeverityWarn, err.Severity(SeverityDefault).HTTPCode(429).GetSeverity())
+}
+
+func TestSeverityText(t *testing.T) {
+	assert.Equal(t, "warn", SeverityWarn.String())
+	assert.Equal(t, "Severity(42)", Severity(42).String())
+
+	var s Severity
+	assert.NoError(t, s.UnmarshalText([]byte("Critical")))
+	assert.Equal(t, SeverityCritical, s)
+	assert.Error(t, s.UnmarshalText([]byte("fatal")))
+
+	data, err := json.Marshal(New("warning").HTTPCode(404).Make())
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"severity":"warn"`)
+}
+
+func TestSeverityLogPrefix(t *testing.T) {
+	lb := setLogBuffer()
+	err := New("not found").HTTPCode(404).Make()
+	err.ToLog()
+	assert.True(t, strings.HasPrefix(lb.String(), "[WARN "+err.GetID()+"] not found"))
+
+	lb = setLogBuffer()
+	New("corrupted").Severity(SeverityCritical).Make().Untrack().ForceLog()
+	assert.True(t, strings.HasPrefix(lb.String(), "[CRIT] corrupted"))
+}
+
+func TestMinSeverity(t *testing.T) {
+	MinSeverity = SeverityError
+	defer func() { MinSeverity = SeverityDebug }()
+
+	lb := setLogBuffer()
+	New("not found").HTTPCode(404).Make().ToLog()
+	New("debug").Severity(SeverityDebug).Make().ForceLog()
+	assert.Equal(t, "", lb.String())
+
+	New("internal").Make().ToLog()
+	assert.Contains(t, lb.String(), "internal")
+}
+
+func TestLevelLogger(t *testing.T) {
+	var lines []string
+	LevelLogger = func(severity Severity, msg string, args ...interface{}) {
+		lines = append(lines, severity.String()+": "+fmt.Sprintf(msg, args...))
+	}
+	defer func() { LevelLogger = nil }()
+
+	lb := setLogBuffer()
+	err := New("corrupted").Severity(SeverityCritical).Trace().Make()
+	err.ToLog()
+	assert.Equal(t, "", lb.String(), "Logger should not be called if LevelLogger is set")
+	assert.Equal(t, "critical: [CRIT "+err.GetID()+"] corrupted", lines[0])
+	for _, line := range lines {
+		assert.True(t, strings.HasPrefix(line, "critical: "))
+	}
+}
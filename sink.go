@@ -0,0 +1,193 @@
+package errors
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+// Record is an error that has been passed to the log.
+type Record struct {
+	Error    Error
+	Severity Severity
+	// Time denotes the call to ToLog() or ForceLog().
+	Time time.Time
+}
+
+// Sink receives errors written to log.
+type Sink interface {
+	Write(record Record)
+}
+
+// SinkFunc is a function used as Sink.
+type SinkFunc func(record Record)
+
+// Write calls f(record).
+func (f SinkFunc) Write(record Record) {
+	f(record)
+}
+
+// LogSink receives all errors written to log if set. Logger and LevelLogger are only used if LogSink is nil.
+var LogSink Sink
+
+// NewLoggerSink returns a sink that writes the default log lines of errors to logger.
+func NewLoggerSink(logger func(msg string, args ...interface{})) Sink {
+	return SinkFunc(func(record Record) {
+		writeLogLines(record, FormatOptions{Stack: true}, func(severity Severity, msg string, args ...interface{}) {
+			logger(msg, args...)
+		})
+	})
+}
+
+// writeLogLines writes the error message followed by fingerprint, creation time and metadata, the fields, goroutine and stack trace of the record as separate lines. Safe output only contains the safe message.
+func writeLogLines(record Record, options FormatOptions, logf func(severity Severity, msg string, args ...interface{})) {
+	severity := record.Severity
+	prefix := severity.logPrefix()
+	err, ok := record.Error.(baseError)
+	if !ok || options.SafeOnly {
+		message, id := record.Error.Error(), record.Error.GetID()
+		if options.SafeOnly {
+			message = safeMessage(record.Error)
+		}
+		if ok && !err.flags.track {
+			id = ""
+		}
+		if len(id) == 0 {
+			logf(severity, "[%v] %v", prefix, message)
+		} else {
+			logf(severity, "[%v %v] %v", prefix, id, message)
+		}
+		return
+	}
+
+	if len(err.trace.id) > 0 {
+		message := err.Error()
+		if err.trace.metadata != nil {
+			// keep the default line unchanged for parsers unless metadata has been captured
+			message += " | " + err.metaString()
+		}
+		if !err.flags.track {
+			logf(severity, "[%v] %v", prefix, message)
+		} else {
+			logf(severity, "[%v %v] %v", prefix, err.trace.id, message)
+		}
+		if len(err.content.fields) > 0 {
+			if !err.flags.track {
+				logf(severity, "[FIELDS] %v", err.content.fieldsString())
+			} else {
+				logf(severity, "[FIELDS %v] %v", err.trace.id, err.content.fieldsString())
+			}
+		}
+	}
+	if g, ok := err.GetGoroutine(); ok {
+		if !err.flags.track {
+			logf(severity, "[GOROUTINE] %v", g)
+		} else {
+			logf(severity, "[GOROUTINE %v] %v", err.trace.id, g)
+		}
+	}
+	if !options.Stack {
+		return
+	}
+	if stack := err.renderStack(); len(stack) > 0 {
+		if !err.flags.track {
+			logf(severity, "[STACK] %v", stack)
+		} else {
+			logf(severity, "[STACK %v] %v", err.trace.id, stack)
+		}
+	}
+}
+
+type multiSink []Sink
+
+// MultiSink returns a sink that writes every record to all given sinks in order.
+func MultiSink(sinks ...Sink) Sink {
+	return multiSink(append([]Sink(nil), sinks...))
+}
+
+func (m multiSink) Write(record Record) {
+	for _, sink := range m {
+		sink.Write(record)
+	}
+}
+
+// RecordFilter returns true for records that should be written.
+type RecordFilter func(record Record) bool
+
+// FilterSink returns a sink that only passes records accepted by filter to sink.
+func FilterSink(sink Sink, filter RecordFilter) Sink {
+	return SinkFunc(func(record Record) {
+		if filter(record) {
+			sink.Write(record)
+		}
+	})
+}
+
+// MinSeverityFilter accepts records with at least the given severity.
+func MinSeverityFilter(severity Severity) RecordFilter {
+	return func(record Record) bool {
+		return record.Severity >= severity
+	}
+}
+
+// RuleFilter accepts records that are not skipped by rule.
+func RuleFilter(rule LogRule) RecordFilter {
+	return func(record Record) bool {
+		return rule(record.Error) != Skip
+	}
+}
+
+type writerSink struct {
+	mutex  sync.Mutex
+	w      io.Writer
+	format Formatter
+}
+
+// WriterSink returns a sink that writes formatted records to w. Writes are serialized, so w does not need to be safe for concurrent use.
+func WriterSink(w io.Writer, format Formatter) Sink {
+	return &writerSink{w: w, format: format}
+}
+
+func (s *writerSink) Write(record Record) {
+	data := s.format(record)
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.w.Write(data)
+}
+
+// RingBuffer is a sink that keeps the latest records in memory, e.g. for a debug page. It is safe for concurrent use.
+type RingBuffer struct {
+	mutex   sync.Mutex
+	records []Record
+	next    int
+	full    bool
+}
+
+// NewRingBuffer returns a ring buffer that keeps the given number of records.
+func NewRingBuffer(size int) *RingBuffer {
+	if size < 1 {
+		size = 1
+	}
+	return &RingBuffer{records: make([]Record, size)}
+}
+
+// Write stores the record and replaces the oldest one if the buffer is full.
+func (b *RingBuffer) Write(record Record) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.records[b.next] = record
+	b.next = (b.next + 1) % len(b.records)
+	if b.next == 0 {
+		b.full = true
+	}
+}
+
+// Records returns a copy of all stored records starting with the oldest one.
+func (b *RingBuffer) Records() []Record {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if !b.full {
+		return append([]Record(nil), b.records[:b.next]...)
+	}
+	return append(append([]Record(nil), b.records[b.next:]...), b.records[:b.next]...)
+}
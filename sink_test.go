@@ -0,0 +1,110 @@
+package errors
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMultiSink(t *testing.T) {
+	var text, jsonLines bytes.Buffer
+	ring := NewRingBuffer(10)
+	LogSink = MultiSink(
+		WriterSink(&text, TextFormatter(FormatOptions{Stack: true})),
+		FilterSink(WriterSink(&jsonLines, JSONFormatter(FormatOptions{SafeOnly: true})), MinSeverityFilter(SeverityError)),
+		ring,
+	)
+	defer func() { LogSink = nil }()
+
+	internal := New("Database %s unavailable").Trace().Make().Args("users")
+	notFound := New("User not found").Safe().HTTPCode(404).Make()
+	internal.ToLog()
+	notFound.ToLog()
+
+	assert.Contains(t, text.String(), fmt.Sprintf("[ERR %s] Database users unavailable\n", internal.GetID()))
+	assert.Contains(t, text.String(), fmt.Sprintf("[WARN %s] User not found\n", notFound.GetID()))
+	assert.Contains(t, text.String(), "TestMultiSink")
+
+	lines := strings.Split(strings.TrimSuffix(jsonLines.String(), "\n"), "\n")
+	if assert.Len(t, lines, 1) {
+		var record map[string]interface{}
+		assert.NoError(t, json.Unmarshal([]byte(lines[0]), &record))
+		assert.Equal(t, GenericSafeErrorMessage, record["message"])
+		assert.Equal(t, internal.GetID(), record["id"])
+		assert.Equal(t, "error", record["severity"])
+		assert.Nil(t, record["stack"])
+	}
+
+	records := ring.Records()
+	if assert.Len(t, records, 2) {
+		assert.Equal(t, internal, records[0].Error)
+		assert.Equal(t, notFound, records[1].Error)
+	}
+}
+
+func TestTextFormatter(t *testing.T) {
+	err := New("Secret %s leaked").Trace().Make().Args("token").With("user", "alice")
+	record := Record{err, SeverityCritical, time.Now()}
+
+	full := string(TextFormatter(FormatOptions{Stack: true})(record))
+	assert.True(t, strings.HasPrefix(full, "[CRIT "+err.GetID()+"] Secret token leaked\n[FIELDS "+err.GetID()+"] user=\"alice\"\n"))
+	assert.Contains(t, full, "[STACK "+err.GetID()+"] github.com/sbreitf1/errors.TestTextFormatter\n")
+
+	noStack := string(TextFormatter(FormatOptions{})(record))
+	assert.NotContains(t, noStack, "[STACK")
+	assert.Contains(t, noStack, "[FIELDS")
+
+	safe := string(TextFormatter(FormatOptions{Stack: true, SafeOnly: true})(record))
+	assert.Equal(t, "[CRIT "+err.GetID()+"] "+GenericSafeErrorMessage+"\n", safe)
+}
+
+func TestJSONFormatter(t *testing.T) {
+	err := New("Secret %s leaked").Trace().Make().Args("token")
+	now := time.Date(2024, 5, 2, 10, 0, 0, 0, time.UTC)
+
+	var result struct {
+		Time     time.Time
+		Message  string
+		Severity string
+		Stack    []interface{}
+	}
+	data := JSONFormatter(FormatOptions{Stack: true})(Record{err, SeverityCritical, now})
+	assert.True(t, bytes.HasSuffix(data, []byte("}\n")))
+	assert.Equal(t, 1, bytes.Count(data, []byte("\n")), "Records should be encoded as single line")
+	assert.NoError(t, json.Unmarshal(data, &result))
+	assert.True(t, now.Equal(result.Time))
+	assert.Equal(t, "Secret token leaked", result.Message)
+	assert.Equal(t, "critical", result.Severity)
+	assert.Len(t, result.Stack, 1)
+
+	result.Stack = nil
+	assert.NoError(t, json.Unmarshal(JSONFormatter(FormatOptions{})(Record{err, SeverityCritical, now}), &result))
+	assert.Len(t, result.Stack, 0)
+}
+
+func TestRecordFilters(t *testing.T) {
+	record := Record{New("client").HTTPCode(400).Make(), SeverityWarn, time.Now()}
+	assert.True(t, MinSeverityFilter(SeverityWarn)(record))
+	assert.False(t, MinSeverityFilter(SeverityError)(record))
+	assert.False(t, RuleFilter(SkipHTTPCodesBelow(500))(record))
+	assert.True(t, RuleFilter(LogTraced())(record))
+}
+
+func TestRingBuffer(t *testing.T) {
+	ring := NewRingBuffer(3)
+	assert.Len(t, ring.Records(), 0)
+	for i := 0; i < 5; i++ {
+		ring.Write(testRecord(i))
+	}
+	records := ring.Records()
+	var messages []string
+	for _, record := range records {
+		messages = append(messages, record.Error.Error())
+	}
+	assert.Equal(t, []string{"record 2", "record 3", "record 4"}, messages)
+}
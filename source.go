@@ -0,0 +1,73 @@
+package errors
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"sync"
+)
+
+var (
+	// SourceContext is the number of source lines printed before and after the line of the top frames of every stack level. Source snippets are only printed in DevelopmentMode and TestMode and are disabled by default.
+	SourceContext = 0
+
+	// SourceFrames is the number of frames per stack level that are printed with source snippets.
+	SourceFrames = 1
+)
+
+var sourceCache = struct {
+	sync.Mutex
+	files map[string][]string
+}{files: make(map[string][]string)}
+
+// sourceLines returns the lines of a source file. Files are read only once and missing files are remembered as nil.
+func sourceLines(file string) []string {
+	sourceCache.Lock()
+	defer sourceCache.Unlock()
+	if lines, ok := sourceCache.files[file]; ok {
+		return lines
+	}
+	var lines []string
+	if data, err := ioutil.ReadFile(file); err == nil {
+		lines = strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	}
+	sourceCache.files[file] = lines
+	return lines
+}
+
+// ClearSourceCache removes all cached source files used for snippets.
+func ClearSourceCache() {
+	sourceCache.Lock()
+	defer sourceCache.Unlock()
+	sourceCache.files = make(map[string][]string)
+}
+
+// snippetsEnabled returns true if source snippets should be printed.
+func snippetsEnabled() bool {
+	return SourceContext > 0 && SourceFrames > 0 && CurrentMode != ProductionMode
+}
+
+// snippet returns the source lines around the given line with the line itself highlighted by ">". Returns an empty string if the source file is not available.
+func snippet(file string, line int) string {
+	lines := sourceLines(file)
+	if line < 1 || line > len(lines) {
+		return ""
+	}
+	from, to := line-SourceContext, line+SourceContext
+	if from < 1 {
+		from = 1
+	}
+	if to > len(lines) {
+		to = len(lines)
+	}
+
+	var sb strings.Builder
+	for i := from; i <= to; i++ {
+		marker := " "
+		if i == line {
+			marker = ">"
+		}
+		fmt.Fprintf(&sb, "\n\t%s %5d | %s", marker, i, strings.TrimRight(lines[i-1], " \t\r"))
+	}
+	return sb.String()
+}
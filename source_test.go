@@ -0,0 +1,61 @@
+package errors
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func setSourceContext(t *testing.T, mode Mode, context int) {
+	oldMode, oldContext := CurrentMode, SourceContext
+	CurrentMode, SourceContext = mode, context
+	t.Cleanup(func() { CurrentMode, SourceContext = oldMode, oldContext })
+}
+
+func TestSourceSnippet(t *testing.T) {
+	setSourceContext(t, DevelopmentMode, 1)
+
+	_, _, line, _ := runtime.Caller(0)
+	err := New("snippet").Trace().Make() // failing line
+	str := fmt.Sprintf("%+v", err)
+
+	assert.Contains(t, str, fmt.Sprintf("\n\t  %5d | \t_, _, line, _ := runtime.Caller(0)\n", line))
+	assert.Contains(t, str, fmt.Sprintf("\n\t> %5d | \terr := New(\"snippet\").Trace().Make() // failing line\n", line+1))
+	assert.Contains(t, str, fmt.Sprintf("\n\t  %5d | \tstr := fmt.Sprintf(\"%%+v\", err)\n", line+2))
+	assert.Equal(t, 1, strings.Count(str, "\t> "), "Only the top frame should contain a snippet")
+}
+
+func TestSourceSnippetDisabled(t *testing.T) {
+	setSourceContext(t, ProductionMode, 2)
+	assert.NotContains(t, fmt.Sprintf("%+v", New("snippet").Trace().Make()), "\t> ")
+
+	setSourceContext(t, TestMode, 0)
+	assert.NotContains(t, fmt.Sprintf("%+v", New("snippet").Trace().Make()), "\t> ")
+}
+
+func TestSourceSnippetChain(t *testing.T) {
+	setSourceContext(t, TestMode, 2)
+
+	lb := setLogBuffer()
+	New("handler").Trace().Make().Cause(serviceTestError()).ForceLog()
+	assert.Equal(t, 3, strings.Count(lb.String(), "\t> "))
+	assert.Contains(t, lb.String(), "| \treturn New(\"load\").Trace().Make()")
+}
+
+func TestSourceCache(t *testing.T) {
+	ClearSourceCache()
+	assert.Nil(t, sourceLines("/does/not/exist.go"))
+	_, ok := sourceCache.files["/does/not/exist.go"]
+	assert.True(t, ok, "Missing files should be cached")
+
+	_, file, line, _ := runtime.Caller(0)
+	assert.Equal(t, "\t_, file, line, _ := runtime.Caller(0)", sourceLines(file)[line-1])
+	sourceCache.files[file] = []string{"cached"}
+	assert.Equal(t, []string{"cached"}, sourceLines(file))
+	ClearSourceCache()
+
+	assert.Equal(t, "", snippet("/does/not/exist.go", 1))
+}
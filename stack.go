@@ -0,0 +1,175 @@
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// stackLevel is an error of a cause chain with its own stack trace.
+type stackLevel struct {
+	// cause denotes a level below the logged error.
+	cause    bool
+	message  string
+	stack    []uintptr
+	wrapSite uintptr
+}
+
+// stackLevels returns all traced errors of the cause chain starting with err.
+func (err baseError) stackLevels() []stackLevel {
+	var levels []stackLevel
+	for level, cause := err, false; ; cause = true {
+		if level.flags.trace && len(level.trace.stack) > 0 {
+			message := string(level.errType)
+			if level.content.message != "" {
+				message = level.content.text()
+			}
+			levels = append(levels, stackLevel{cause, message, level.trace.stack, level.trace.wrapSite})
+		}
+		next, ok := level.content.cause.(baseError)
+		if !ok {
+			return levels
+		}
+		level = next
+	}
+}
+
+// renderStack returns the stack traces of the error and all traced causes filtered by StackFilter. The innermost stack is printed completely and outer levels only print the frames that differ from the next inner stack followed by "... N more" like Java. Source snippets are added to the top frames of each level if enabled by SourceContext. Returns an empty string if no stack trace is available.
+func (err baseError) renderStack() string {
+	levels := err.stackLevels()
+	var sb strings.Builder
+	for i, level := range levels {
+		if level.cause {
+			fmt.Fprintf(&sb, "\ncaused by: %s", level.message)
+		}
+		snippets := 0
+		if snippetsEnabled() {
+			snippets = SourceFrames
+		}
+		for _, line := range level.lines(levels[i+1:]) {
+			sb.WriteString("\n")
+			sb.WriteString(line.text())
+			if line.skipped == 0 && snippets > 0 {
+				sb.WriteString(snippet(line.source, line.line))
+				snippets--
+			}
+		}
+		if level.wrapSite != 0 {
+			fmt.Fprintf(&sb, "\nwrapped at %s", StackFilter.line(level.wrapSite).text())
+		}
+	}
+	return strings.TrimPrefix(sb.String(), "\n")
+}
+
+// lines returns the filtered frames of the level without the frames shared with the next inner level.
+func (level stackLevel) lines(inner []stackLevel) []frameLine {
+	frames, common := level.stack, 0
+	if len(inner) > 0 {
+		common = commonFrames(frames, inner[0].stack)
+	}
+	return StackFilter.apply(frames[:len(frames)-common], common)
+}
+
+// commonFrames returns the number of equal outermost frames of two stacks.
+func commonFrames(outer, inner []uintptr) int {
+	n := 0
+	for n < len(outer) && n < len(inner) && outer[len(outer)-1-n] == inner[len(inner)-1-n] {
+		n++
+	}
+	return n
+}
+
+type jsonError struct {
+	Type        ErrorType              `json:"type"`
+	Message     string                 `json:"message"`
+	SafeMessage string                 `json:"safeMessage"`
+	ID          string                 `json:"id,omitempty"`
+	HTTPCode    int                    `json:"httpCode"`
+	ErrCode     int                    `json:"errCode"`
+	Severity    Severity               `json:"severity"`
+	Fingerprint string                 `json:"fingerprint"`
+	Tags        map[string]interface{} `json:"tags,omitempty"`
+	Fields      map[string]interface{} `json:"fields,omitempty"`
+	Causes      []jsonCause            `json:"causes,omitempty"`
+	CreatedAt   time.Time              `json:"createdAt"`
+	Metadata    *Metadata              `json:"metadata,omitempty"`
+	Goroutine   *Goroutine             `json:"goroutine,omitempty"`
+	Stack       []jsonStackLevel       `json:"stack,omitempty"`
+}
+
+type jsonCause struct {
+	Type    ErrorType `json:"type"`
+	Message string    `json:"message"`
+}
+
+type jsonStackLevel struct {
+	// Cause contains the message of a traced cause.
+	Cause     string   `json:"cause,omitempty"`
+	Frames    []string `json:"frames"`
+	WrappedAt string   `json:"wrappedAt,omitempty"`
+}
+
+// MarshalJSON encodes the safe representation of the error with type, safe message, id, codes, severity, fingerprint and creation time. Use JSONFormatter to encode the unsafe message, tags, fields, causes, execution context and stack traces.
+func (err baseError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(err.toJSON(FormatOptions{SafeOnly: true}))
+}
+
+// toJSON returns the JSON representation of the error. Safe representations only contain the safe message and no tags, causes, fields, stack traces or execution context.
+func (err baseError) toJSON(options FormatOptions) jsonError {
+	j := jsonError{
+		Type:        err.errType,
+		Message:     safeMessage(err),
+		SafeMessage: safeMessage(err),
+		ID:          err.trace.id,
+		HTTPCode:    err.api.httpCode,
+		ErrCode:     err.api.errCode,
+		Severity:    err.GetSeverity(),
+		Fingerprint: err.Fingerprint(),
+		CreatedAt:   err.trace.createdAt,
+	}
+	if options.SafeOnly {
+		return j
+	}
+
+	j.Message = err.Error()
+	if len(err.flags.tags) > 0 {
+		j.Tags = make(map[string]interface{}, len(err.flags.tags))
+		for tag, val := range err.flags.tags {
+			j.Tags[tag] = val
+		}
+	}
+	j.Fields = err.GetFields()
+	if len(j.Fields) == 0 {
+		j.Fields = nil
+	}
+	for cause := err.content.cause; cause != nil; {
+		message := cause.Error()
+		if c, ok := cause.(baseError); ok {
+			message = string(c.errType)
+			if c.content.message != "" {
+				message = c.content.text()
+			}
+		}
+		j.Causes = append(j.Causes, jsonCause{cause.GetType(), message})
+		cause, _ = cause.Unwrap().(Error)
+	}
+	j.Metadata, j.Goroutine = err.trace.metadata, err.trace.goroutine
+
+	if options.Stack {
+		levels := err.stackLevels()
+		j.Stack = make([]jsonStackLevel, len(levels))
+		for i, level := range levels {
+			if level.cause {
+				j.Stack[i].Cause = level.message
+			}
+			for _, line := range level.lines(levels[i+1:]) {
+				j.Stack[i].Frames = append(j.Stack[i].Frames, line.String())
+			}
+			if level.wrapSite != 0 {
+				j.Stack[i].WrappedAt = StackFilter.line(level.wrapSite).String()
+			}
+		}
+	}
+	return j
+}
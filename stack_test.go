@@ -0,0 +1,63 @@
+package errors
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func loadTestError() Error {
+	return New("load").Trace().Make()
+}
+
+func serviceTestError() Error {
+	return New("service").Trace().Make().Cause(loadTestError())
+}
+
+func TestRenderStackChain(t *testing.T) {
+	err := New("handler").Trace().Make().Cause(serviceTestError())
+	stack := err.(baseError).renderStack()
+
+	lines := strings.Split(stack, "\n")
+	assert.Equal(t, "github.com/sbreitf1/errors.TestRenderStackChain", lines[0])
+	assert.Equal(t, 1, strings.Count(stack, "testing.tRunner"), "Shared frames should only be printed once")
+	assert.Equal(t, 2, strings.Count(stack, " more"))
+	assert.Contains(t, stack, "\ncaused by: service\ngithub.com/sbreitf1/errors.serviceTestError\n\t")
+	assert.Contains(t, stack, "\ncaused by: load\ngithub.com/sbreitf1/errors.loadTestError\n\t")
+
+	// frames of the outer levels are printed until the first shared frame
+	handler := stack[:strings.Index(stack, "caused by: service")]
+	assert.Contains(t, handler, "\n\t... ")
+	assert.Equal(t, 1, strings.Count(handler, "TestRenderStackChain"))
+
+	// innermost stack is printed completely
+	load := stack[strings.Index(stack, "caused by: load"):]
+	assert.NotContains(t, load, " more")
+	assert.Contains(t, load, "TestRenderStackChain")
+	assert.Contains(t, load, "testing.tRunner")
+}
+
+func TestRenderStackUntracedCause(t *testing.T) {
+	err := New("outer").Trace().Make().Cause(New("inner").NoTrace().Make())
+	stack := err.(baseError).renderStack()
+	assert.NotContains(t, stack, "caused by")
+	assert.NotContains(t, stack, " more")
+	assert.Contains(t, stack, "testing.tRunner")
+
+	assert.Equal(t, "", New("untraced").NoTrace().Make().(baseError).renderStack())
+}
+
+func TestFormatStackChain(t *testing.T) {
+	err := New("outer").NoTrace().Make().Cause(loadTestError())
+	assert.True(t, strings.HasPrefix(fmt.Sprintf("%+v", err), "outer: load\ncaused by: load\ngithub.com/sbreitf1/errors.loadTestError\n\t"))
+}
+
+func TestForceLogStackChain(t *testing.T) {
+	err := New("handler").Trace().Make().Cause(serviceTestError())
+	lb := setLogBuffer()
+	err.ForceLog()
+	assert.Equal(t, 1, strings.Count(lb.String(), "testing.tRunner"))
+	assert.Contains(t, lb.String(), "caused by: load")
+}
@@ -3,6 +3,7 @@ package errors
 import (
 	"crypto/sha1"
 	"fmt"
+	"runtime"
 	"runtime/debug"
 	"strings"
 	"time"
@@ -15,11 +16,23 @@ var (
 	ConfigurationError = New("The specified configuration is not valid")
 	// ArgumentError denotes a missing or invalid argument.
 	ArgumentError = New("An invalid argument has been supplied")
+	// CatalogError denotes a message catalog file that could not be loaded.
+	CatalogError = NewTyped("errors.CatalogError", "Unable to load message catalog %q")
+	// CatalogFormatError denotes malformed message catalog data.
+	CatalogFormatError = NewTyped("errors.CatalogFormatError", "Invalid message catalog format")
+	// RegistryTypeError denotes a template type that has already been registered.
+	RegistryTypeError = NewTyped("errors.RegistryTypeError", "Error type %q is already registered")
+	// RegistryCodeError denotes an api error code that has already been registered by an unrelated template.
+	RegistryCodeError = NewTyped("errors.RegistryCodeError", "Error code %d of %q is already registered by %q")
+	// SuppressedError summarizes occurrences suppressed by a LogLimiter, if LogSink is set.
+	SuppressedError = NewTyped("errors.SuppressedError", "suppressed %d occurrences of %s in last %v").Severity(SeverityWarn).Untrack()
 )
 
 // Template represents an error template that can be instatiated to an error using Make().
 type Template struct {
 	errType ErrorType
+	// parents contains the types of all ancestor templates starting with the direct parent.
+	parents []ErrorType
 	content content
 	flags   flags
 	api     apiData
@@ -27,15 +40,19 @@ type Template struct {
 
 // New returns an error template and uses the message format string as error type.
 func New(msg string, args ...interface{}) Template {
-	content := content{message: msg, cause: nil}
-	if len(args) > 0 {
-		// hack: go-vet erroneously detects missing args when calling Sprintf directly
-		// -> using the encapsulation prevents go-vet from processing the format string
-		content.message = fmt.Sprintf(fmt.Sprintf("%s", msg), args...)
-	}
+	return newTemplate(ErrorType(msg), msg, args)
+}
+
+// NewTyped returns an error template with an explicit error type that is independent of the message. Use namespaced identifiers like "storage.NotFound" to avoid collisions between packages.
+func NewTyped(errType string, msg string, args ...interface{}) Template {
+	return newTemplate(ErrorType(errType), msg, args)
+}
+
+func newTemplate(errType ErrorType, msg string, args []interface{}) Template {
+	content := content{templated: true}.withMessage(errType, msg, args)
 	flags := flags{track: true, trace: false, isSafe: false, tags: make(map[string]interface{})}
-	api := apiData{defaultHTTPCode, defaultErrCode}
-	return Template{ErrorType(msg), content, flags, api}
+	api := apiData{defaultHTTPCode, defaultErrCode, ""}
+	return Template{errType, nil, content, flags, api}
 }
 
 // GetType returns the underlying error type of this template.
@@ -43,11 +60,97 @@ func (t Template) GetType() ErrorType {
 	return t.errType
 }
 
+// Derive returns a child template of the given type. The child inherits message, flags, tags and codes from this template and is matched by IsKindOf(parent).
+func (t Template) Derive(errType string) Template {
+	parents := make([]ErrorType, 0, len(t.parents)+1)
+	parents = append(parents, t.errType)
+	parents = append(parents, t.parents...)
+	flags := t.flags
+	flags.tags = make(map[string]interface{}, len(t.flags.tags))
+	for tag, val := range t.flags.tags {
+		flags.tags[tag] = val
+	}
+	content := t.content
+	content.inherited = true
+	return Template{ErrorType(errType), parents, content, flags, t.api}
+}
+
+// GetMessage returns the message format string of this template.
+func (t Template) GetMessage() string {
+	return t.content.message
+}
+
+// GetHTTPCode returns the http response code of this template.
+func (t Template) GetHTTPCode() int {
+	return t.api.httpCode
+}
+
+// GetErrCode returns the api error code of this template.
+func (t Template) GetErrCode() int {
+	return t.api.errCode
+}
+
+// IsSafe returns true if errors of this template are safe for printing to end-user.
+func (t Template) IsSafe() bool {
+	return t.flags.isSafe
+}
+
+// GetTags returns a copy of all tags of this template. Tags without value are mapped to nil.
+func (t Template) GetTags() map[string]interface{} {
+	tags := make(map[string]interface{}, len(t.flags.tags))
+	for tag, val := range t.flags.tags {
+		tags[tag] = val
+	}
+	return tags
+}
+
+// GetParentTypes returns the types of all ancestors starting with the direct parent.
+func (t Template) GetParentTypes() []ErrorType {
+	return append([]ErrorType(nil), t.parents...)
+}
+
+// IsKindOf returns true if this template equals the given one or is derived from it.
+func (t Template) IsKindOf(parent TypedError) bool {
+	return isKindOf(t.errType, t.parents, parent.GetType())
+}
+
+// Qualify prefixes the error type with the import path of the calling package like "github.com/foo/storage.NotFound". Call this method directly in the template definition.
+func (t Template) Qualify() Template {
+	errType := ErrorType(callerPackage(1) + "." + string(t.errType))
+	api := t.api
+	if api.codeOwner == t.errType {
+		api.codeOwner = errType
+	}
+	return Template{errType, t.parents, t.content, t.flags, api}
+}
+
+// callerPackage returns the import path of the package containing the calling function. Depth 0 denotes the caller of callerPackage.
+func callerPackage(depth int) string {
+	pc, _, _, ok := runtime.Caller(depth + 1)
+	if !ok {
+		return ""
+	}
+	fn := runtime.FuncForPC(pc)
+	if fn == nil {
+		return ""
+	}
+	return funcPackage(fn.Name())
+}
+
+// funcPackage returns the package path of a fully qualified function name like "github.com/foo/bar.(*T).Method".
+func funcPackage(funcName string) string {
+	lastSlash := strings.LastIndex(funcName, "/")
+	if dot := strings.Index(funcName[lastSlash+1:], "."); dot >= 0 {
+		return funcName[:lastSlash+1+dot]
+	}
+	return funcName
+}
+
 // Track enables id printing for this error.
 func (t Template) Track() Template {
 	flags := t.flags
 	flags.track = true
-	return Template{t.errType, t.content, flags, t.api}
+	return Template{t.errType, t.parents, t.content, flags, t.api}
 }
 
 // Untrack disabled id and stack trace printing for this error.
@@ -55,7 +158,7 @@ func (t Template) Untrack() Template {
 	flags := t.flags
 	flags.track = false
 	flags.trace = false
-	return Template{t.errType, t.content, flags, t.api}
+	return Template{t.errType, t.parents, t.content, flags, t.api}
 }
 
 // Trace enables stack trace printing.
@@ -63,62 +166,61 @@ func (t Template) Trace() Template {
 	flags := t.flags
 	flags.track = true
 	flags.trace = true
-	return Template{t.errType, t.content, flags, t.api}
+	return Template{t.errType, t.parents, t.content, flags, t.api}
 }
 
 // NoTrace disables stack trace printing.
 func (t Template) NoTrace() Template {
 	flags := t.flags
 	flags.trace = false
-	return Template{t.errType, t.content, flags, t.api}
+	return Template{t.errType, t.parents, t.content, flags, t.api}
 }
 
 // Safe marks the error as safe for printing to end-user.
 func (t Template) Safe() Template {
 	flags := t.flags
 	flags.isSafe = true
-	return Template{t.errType, t.content, flags, t.api}
+	return Template{t.errType, t.parents, t.content, flags, t.api}
 }
 
-// Msg replaces the error message. You can supply all formatting args later using Args() to skip formatting in this call.
+// Msg replaces the error message. You can supply all formatting args later using Args() to skip formatting in this call. Replaced messages are not localized, except for the first message of a derived template, which defines its message.
 func (t Template) Msg(msg string, args ...interface{}) Template {
-	content := t.content
-	if len(args) == 0 {
-		content.message = msg
-	} else {
-		// hack: go-vet erroneously detects missing args when calling Sprintf directly
-		// -> using the encapsulation prevents go-vet from processing the format string
-		content.message = fmt.Sprintf(fmt.Sprintf("%s", msg), args...)
-	}
-	return Template{t.errType, content, t.flags, t.api}
+	content := t.content.withMessage(t.errType, msg, args)
+	content.templated = t.content.inherited
+	content.inherited = false
+	return Template{t.errType, t.parents, content, t.flags, t.api}
 }
 
 // Args fills the message placeholders with the given arguments.
 func (t Template) Args(args ...interface{}) Template {
-	content := t.content
-	content.message = fmt.Sprintf(content.message, args...)
-	return Template{t.errType, content, t.flags, t.api}
+	content := t.content.withArgs(t.errType, args)
+	return Template{t.errType, t.parents, content, t.flags, t.api}
+}
+
+// With sets the value for the named placeholder {name}. The value is also printed to log as structured field.
+func (t Template) With(name string, value interface{}) Template {
+	return Template{t.errType, t.parents, t.content.withField(name, value), t.flags, t.api}
 }
 
 // Tag adds a named tag to the template.
 func (t Template) Tag(tag string) Template {
 	flags := t.flags
 	flags.tags[tag] = nil
-	return Template{t.errType, t.content, flags, t.api}
+	return Template{t.errType, t.parents, t.content, flags, t.api}
 }
 
 // TagStr adds a named tag with string value to the template.
 func (t Template) TagStr(tag, value string) Template {
 	flags := t.flags
 	flags.tags[tag] = value
-	return Template{t.errType, t.content, flags, t.api}
+	return Template{t.errType, t.parents, t.content, flags, t.api}
 }
 
 // TagInt adds a named tag with integer value to the template.
 func (t Template) TagInt(tag string, value int) Template {
 	flags := t.flags
 	flags.tags[tag] = value
-	return Template{t.errType, t.content, flags, t.api}
+	return Template{t.errType, t.parents, t.content, flags, t.api}
 }
 
 // API untracks the error, marks it as safe and update the error and response codes.
@@ -130,21 +232,23 @@ func (t Template) API(httpCode, errCode int) Template {
 	api := t.api
 	api.httpCode = httpCode
 	api.errCode = errCode
-	return Template{t.errType, t.content, flags, api}
+	api.codeOwner = t.errType
+	return Template{t.errType, t.parents, t.content, flags, api}
 }
 
 // HTTPCode sets the http response code.
 func (t Template) HTTPCode(code int) Template {
 	api := t.api
 	api.httpCode = code
-	return Template{t.errType, t.content, t.flags, api}
+	return Template{t.errType, t.parents, t.content, t.flags, api}
 }
 
 // ErrCode sets the api error code.
 func (t Template) ErrCode(code int) Template {
 	api := t.api
 	api.errCode = code
-	return Template{t.errType, t.content, t.flags, api}
+	api.codeOwner = t.errType
+	return Template{t.errType, t.parents, t.content, t.flags, api}
 }
 
 // Make instatiates an error using this template. A call to this method generates a new ID and StackTrace from the calling location if tracked and traced.
@@ -152,19 +256,31 @@ func (t Template) Make() Error {
 	return t.make(1)
 }
 
+// MakeDepth instatiates an error like Make() but skips the given number of additional stack frames. Use this method in helper functions that construct errors.
+func (t Template) MakeDepth(depth int) Error {
+	return t.make(depth + 1)
+}
+
 // MakeTraced instatiates an error using this template. A call to this method tracks and traces the error and generates a new ID and StackTrace from the calling location. Use the depth parameter to skip a certain number of stack frames in the trace.
 func (t Template) MakeTraced(depth int) Error {
 	return t.Trace().make(depth + 1)
 }
 
 func (t Template) make(depth int) Error {
-	trace := trace{generateID(t.errType, t.content.message), getStackTrace(depth + 1)}
-	return baseError{t.errType, t.content, t.flags, trace, t.api}
+	now := time.Now()
+	trace := trace{generateID(t.errType, t.content.message, now), getStackTrace(depth + 1), callers(depth + 1), 0, nil, now, nil}
+	if CaptureGoroutine {
+		trace.goroutine = parseGoroutine(trace.stackTrace)
+	}
+	if CaptureMetadata {
+		trace.metadata = currentMetadata()
+	}
+	return baseError{t.errType, t.parents, t.content, t.flags, trace, t.api}
 }
 
-func generateID(errType ErrorType, message string) string {
+func generateID(errType ErrorType, message string, now time.Time) string {
 	h := sha1.New()
-	h.Write([]byte(fmt.Sprintf("%v|%v|%v", errType, message, time.Now())))
+	h.Write([]byte(fmt.Sprintf("%v|%v|%v", errType, message, now)))
 	hash := h.Sum(nil)
 	return fmt.Sprintf("%x", hash[:8])
 }
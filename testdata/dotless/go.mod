@@ -0,0 +1,7 @@
+module myservice
+
+go 1.16
+
+require github.com/sbreitf1/errors v0.0.0
+
+replace github.com/sbreitf1/errors => ../..
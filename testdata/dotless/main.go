@@ -0,0 +1,12 @@
+package main
+
+import (
+	"fmt"
+
+	"myservice/storage"
+)
+
+func main() {
+	fmt.Println(storage.Load().Fingerprint())
+	fmt.Println(storage.Save().Fingerprint())
+}
@@ -0,0 +1,14 @@
+package storage
+
+import "github.com/sbreitf1/errors"
+
+// Unavailable is returned from different call sites in a module path without dot.
+var Unavailable = errors.New("Database unavailable").Trace()
+
+func Load() errors.Error {
+	return Unavailable.Make()
+}
+
+func Save() errors.Error {
+	return Unavailable.Make()
+}
@@ -0,0 +1,318 @@
+// Package analyzer provides go/analysis checks for common misuse of templates and errors of the package github.com/sbreitf1/errors.
+package analyzer
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/types"
+	"reflect"
+
+	"github.com/sbreitf1/errors/internal/msgformat"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// LibraryPath is the import path of the analyzed errors package.
+const LibraryPath = "github.com/sbreitf1/errors"
+
+// Analyzers contains all checks of this package.
+var Analyzers = []*analysis.Analyzer{
+	TemplateScope,
+	MakeCall,
+	ArgCount,
+	MsgSafe,
+	Unhandled,
+}
+
+// Templates evaluates all package level templates and exports their format string and safeness as facts. It is a prerequisite of other analyzers and does not report diagnostics.
+var Templates = &analysis.Analyzer{
+	Name:       "errtemplates",
+	Doc:        "collect facts about package level error templates",
+	Run:        runTemplates,
+	Requires:   []*analysis.Analyzer{inspect.Analyzer},
+	FactTypes:  []analysis.Fact{new(TemplateFact)},
+	ResultType: reflect.TypeOf((*TemplateInfos)(nil)),
+}
+
+// TemplateFact describes a package level template variable.
+type TemplateFact struct {
+	// Format is the message format string. Only valid if HasFormat is set.
+	Format    string
+	HasFormat bool
+	// ArgsSet denotes that args have already been applied.
+	ArgsSet bool
+	Safe    bool
+}
+
+// AFact marks TemplateFact as analysis fact.
+func (*TemplateFact) AFact() {}
+
+func (f *TemplateFact) String() string {
+	if !f.HasFormat {
+		return fmt.Sprintf("template(safe=%v)", f.Safe)
+	}
+	return fmt.Sprintf("template(%q, safe=%v)", f.Format, f.Safe)
+}
+
+// TemplateInfos is the result of the Templates analyzer.
+type TemplateInfos struct {
+	lib   *library
+	vars  map[*types.Var]TemplateFact
+	decls map[*types.Var]ast.Expr
+	busy  map[*types.Var]bool
+	info  *types.Info
+}
+
+// library contains the relevant objects of the errors package.
+type library struct {
+	pkg      *types.Package
+	template types.Type
+	err      types.Type
+	aborter  *types.Interface
+}
+
+// findLibrary returns the errors package if it is imported directly or indirectly by pkg.
+func findLibrary(pkg *types.Package) *library {
+	var find func(pkg *types.Package, visited map[*types.Package]bool) *types.Package
+	find = func(pkg *types.Package, visited map[*types.Package]bool) *types.Package {
+		if pkg.Path() == LibraryPath {
+			return pkg
+		}
+		visited[pkg] = true
+		for _, imp := range pkg.Imports() {
+			if visited[imp] {
+				continue
+			}
+			if lib := find(imp, visited); lib != nil {
+				return lib
+			}
+		}
+		return nil
+	}
+
+	libPkg := find(pkg, make(map[*types.Package]bool))
+	if libPkg == nil {
+		return nil
+	}
+	lib := &library{pkg: libPkg}
+	if obj, ok := libPkg.Scope().Lookup("Template").(*types.TypeName); ok {
+		lib.template = obj.Type()
+	}
+	if obj, ok := libPkg.Scope().Lookup("Error").(*types.TypeName); ok {
+		lib.err = obj.Type()
+	}
+	if obj, ok := libPkg.Scope().Lookup("RequestAborter").(*types.TypeName); ok {
+		lib.aborter, _ = obj.Type().Underlying().(*types.Interface)
+	}
+	if lib.template == nil || lib.err == nil {
+		return nil
+	}
+	return lib
+}
+
+func (lib *library) isTemplate(t types.Type) bool {
+	return t != nil && types.Identical(t, lib.template)
+}
+
+func (lib *library) isError(t types.Type) bool {
+	return t != nil && types.Identical(t, lib.err)
+}
+
+// isFunc returns true if the call invokes the named package level function of the errors package.
+func (lib *library) isFunc(info *types.Info, call *ast.CallExpr, names ...string) bool {
+	fn, ok := typeutil.Callee(info, call).(*types.Func)
+	if !ok || fn.Pkg() != lib.pkg || fn.Type().(*types.Signature).Recv() != nil {
+		return false
+	}
+	for _, name := range names {
+		if fn.Name() == name {
+			return true
+		}
+	}
+	return false
+}
+
+// method returns the receiver expression and method name if the call invokes a method of Template or Error.
+func (lib *library) method(info *types.Info, call *ast.CallExpr) (ast.Expr, string, bool) {
+	sel, ok := ast.Unparen(call.Fun).(*ast.SelectorExpr)
+	if !ok {
+		return nil, "", false
+	}
+	selection, ok := info.Selections[sel]
+	if !ok || selection.Kind() != types.MethodVal {
+		return nil, "", false
+	}
+	recv := selection.Recv()
+	if !lib.isTemplate(recv) && !lib.isError(recv) {
+		return nil, "", false
+	}
+	return sel.X, sel.Sel.Name, true
+}
+
+func runTemplates(pass *analysis.Pass) (interface{}, error) {
+	infos := &TemplateInfos{
+		lib:   findLibrary(pass.Pkg),
+		vars:  make(map[*types.Var]TemplateFact),
+		decls: make(map[*types.Var]ast.Expr),
+		busy:  make(map[*types.Var]bool),
+		info:  pass.TypesInfo,
+	}
+	if infos.lib == nil {
+		return infos, nil
+	}
+
+	for _, file := range pass.Files {
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				vspec, ok := spec.(*ast.ValueSpec)
+				if !ok || len(vspec.Names) != len(vspec.Values) {
+					continue
+				}
+				for i, name := range vspec.Names {
+					if v, ok := pass.TypesInfo.Defs[name].(*types.Var); ok && infos.lib.isTemplate(v.Type()) {
+						infos.decls[v] = vspec.Values[i]
+					}
+				}
+			}
+		}
+	}
+
+	// import facts of templates declared in other packages
+	for _, obj := range pass.TypesInfo.Uses {
+		v, ok := obj.(*types.Var)
+		if !ok || v.Pkg() == nil || v.Pkg() == pass.Pkg || v.Parent() != v.Pkg().Scope() {
+			continue
+		}
+		var fact TemplateFact
+		if pass.ImportObjectFact(v, &fact) {
+			infos.vars[v] = fact
+		}
+	}
+
+	for v := range infos.decls {
+		if fact, ok := infos.lookup(v); ok {
+			f := fact
+			pass.ExportObjectFact(v, &f)
+		}
+	}
+	return infos, nil
+}
+
+// lookup returns the fact of a package level template variable.
+func (infos *TemplateInfos) lookup(v *types.Var) (TemplateFact, bool) {
+	if fact, ok := infos.vars[v]; ok {
+		return fact, true
+	}
+	expr, ok := infos.decls[v]
+	if !ok || infos.busy[v] {
+		return TemplateFact{}, false
+	}
+	infos.busy[v] = true
+	fact, ok := infos.eval(expr)
+	infos.busy[v] = false
+	if ok {
+		infos.vars[v] = fact
+	}
+	return fact, ok
+}
+
+// eval statically evaluates a template or error expression built from package level templates, New, NewTyped and mutator calls.
+func (infos *TemplateInfos) eval(expr ast.Expr) (TemplateFact, bool) {
+	if infos.lib == nil {
+		return TemplateFact{}, false
+	}
+	switch e := ast.Unparen(expr).(type) {
+	case *ast.Ident:
+		if v, ok := infos.info.Uses[e].(*types.Var); ok && v.Pkg() != nil && v.Parent() == v.Pkg().Scope() {
+			return infos.lookup(v)
+		}
+	case *ast.SelectorExpr:
+		if v, ok := infos.info.Uses[e.Sel].(*types.Var); ok && v.Pkg() != nil && v.Parent() == v.Pkg().Scope() {
+			return infos.lookup(v)
+		}
+	case *ast.CallExpr:
+		if infos.lib.isFunc(infos.info, e, "New", "NewTyped") {
+			msgIndex := 0
+			if typeutil.Callee(infos.info, e).Name() == "NewTyped" {
+				msgIndex = 1
+			}
+			return infos.withMessage(TemplateFact{}, e, msgIndex), true
+		}
+		recv, name, ok := infos.lib.method(infos.info, e)
+		if !ok {
+			return TemplateFact{}, false
+		}
+		fact, ok := infos.eval(recv)
+		if !ok {
+			return fact, false
+		}
+		isErr := infos.lib.isError(infos.info.TypeOf(recv))
+		switch name {
+		case "Msg":
+			fact = infos.withMessage(fact, e, 0)
+			if isErr {
+				fact.Safe = false
+			}
+		case "Expand", "ExpandSafe":
+			fact = infos.withMessage(fact, e, 0)
+			// expanded messages are formatted immediately, even without args
+			fact.ArgsSet = true
+			fact.Safe = name == "ExpandSafe"
+		case "Args":
+			fact.ArgsSet = true
+		case "Safe", "API":
+			fact.Safe = true
+		}
+		return fact, true
+	}
+	return TemplateFact{}, false
+}
+
+// withMessage applies the message argument of a call to the fact.
+func (infos *TemplateInfos) withMessage(fact TemplateFact, call *ast.CallExpr, msgIndex int) TemplateFact {
+	fact.Format, fact.HasFormat = "", false
+	fact.ArgsSet = len(call.Args) > msgIndex+1 || call.Ellipsis.IsValid()
+	if msgIndex < len(call.Args) {
+		if tv, ok := infos.info.Types[call.Args[msgIndex]]; ok && tv.Value != nil && tv.Value.Kind() == constant.String {
+			fact.Format, fact.HasFormat = constant.StringVal(tv.Value), true
+		}
+	}
+	return fact
+}
+
+// argCountMismatch returns the number of args required by a format string and true, if count args do not match like reported by the errors package at runtime. Invalid format strings are not reported.
+func argCountMismatch(format string, count int) (int, bool) {
+	spec := msgformat.Parse(format)
+	if spec.Problem != "" {
+		return 0, false
+	}
+	return spec.ArgCount, count < spec.ArgCount || (count > spec.ArgCount && !spec.Reordered)
+}
+
+// enclosingFunc returns the innermost function type of an inspector stack.
+func enclosingFunc(info *types.Info, stack []ast.Node) *types.Signature {
+	for i := len(stack) - 1; i >= 0; i-- {
+		switch fn := stack[i].(type) {
+		case *ast.FuncDecl:
+			if obj, ok := info.Defs[fn.Name].(*types.Func); ok {
+				return obj.Type().(*types.Signature)
+			}
+			return nil
+		case *ast.FuncLit:
+			sig, _ := info.TypeOf(fn).(*types.Signature)
+			return sig
+		}
+	}
+	return nil
+}
+
+func inspectorOf(pass *analysis.Pass) *inspector.Inspector {
+	return pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+}
@@ -0,0 +1,55 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestTemplates(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), Templates, "./lib")
+}
+
+func TestTemplateScope(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), TemplateScope, "./scope")
+}
+
+func TestMakeCall(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), MakeCall, "./makecall")
+}
+
+func TestArgCount(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), ArgCount, "./argcount")
+}
+
+func TestMsgSafe(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), MsgSafe, "./msgsafe")
+}
+
+func TestUnhandled(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), Unhandled, "./handlers")
+}
+
+func TestArgCountMismatch(t *testing.T) {
+	for format, expected := range map[string]int{
+		"plain":          0,
+		"%d%%":           1,
+		"%s %v %q":       3,
+		"%*d":            2,
+		"%[2]d %[1]s":    2,
+		"%[1]s %[1]q %s": 2,
+		"{name} %-5.2f":  1,
+	} {
+		count, ok := argCountMismatch(format, expected)
+		assert.False(t, ok, format)
+		assert.Equal(t, expected, count, format)
+		_, ok = argCountMismatch(format, expected+1)
+		assert.Equal(t, format != "%[2]d %[1]s" && format != "%[1]s %[1]q %s", ok, format)
+	}
+	_, ok := argCountMismatch("trailing %", 0)
+	assert.False(t, ok)
+	_, ok = argCountMismatch("%d", 0)
+	assert.True(t, ok)
+}
@@ -0,0 +1,93 @@
+package analyzer
+
+import (
+	"go/ast"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+)
+
+// ArgCount reports a mismatch between format verbs and supplied arguments.
+var ArgCount = &analysis.Analyzer{
+	Name:     "errargcount",
+	Doc:      "report a mismatch between format verbs and arguments of templates and errors\n\nThe message format of templates is resolved statically from package level declarations, also across packages. Calls to Args() are checked against the format verbs, as well as New, NewTyped and Msg with arguments. Expand and ExpandSafe are also checked without arguments, because they format the message immediately. Args() on a message with already applied arguments is reported, too.",
+	Run:      runArgCount,
+	Requires: []*analysis.Analyzer{inspect.Analyzer, Templates},
+}
+
+func runArgCount(pass *analysis.Pass) (interface{}, error) {
+	infos := pass.ResultOf[Templates].(*TemplateInfos)
+	lib := infos.lib
+	if lib == nil {
+		return nil, nil
+	}
+
+	inspectorOf(pass).Preorder([]ast.Node{(*ast.CallExpr)(nil)}, func(n ast.Node) {
+		call := n.(*ast.CallExpr)
+		if call.Ellipsis.IsValid() {
+			return
+		}
+
+		if lib.isFunc(pass.TypesInfo, call, "New") {
+			checkFormatArgs(pass, infos, call, 0)
+			return
+		}
+		if lib.isFunc(pass.TypesInfo, call, "NewTyped") {
+			checkFormatArgs(pass, infos, call, 1)
+			return
+		}
+
+		recv, name, ok := lib.method(pass.TypesInfo, call)
+		if !ok {
+			return
+		}
+		switch name {
+		case "Msg":
+			checkFormatArgs(pass, infos, call, 0)
+		case "Expand", "ExpandSafe":
+			checkExpandArgs(pass, infos, call)
+		case "Args":
+			fact, ok := infos.eval(recv)
+			if !ok || !fact.HasFormat {
+				return
+			}
+			if fact.ArgsSet {
+				pass.ReportRangef(call, "Args() called on %s with already applied arguments: the arguments are ignored", types.ExprString(recv))
+				return
+			}
+			if expected, ok := argCountMismatch(fact.Format, len(call.Args)); ok {
+				pass.ReportRangef(call, "Args() called with %d arguments but format %q expects %d", len(call.Args), fact.Format, expected)
+			}
+		}
+	})
+	return nil, nil
+}
+
+// checkFormatArgs checks the arguments following the message argument at msgIndex. Calls without arguments are skipped, because args can be applied later.
+func checkFormatArgs(pass *analysis.Pass, infos *TemplateInfos, call *ast.CallExpr, msgIndex int) {
+	if len(call.Args) <= msgIndex+1 {
+		return
+	}
+	fact := infos.withMessage(TemplateFact{}, call, msgIndex)
+	if !fact.HasFormat {
+		return
+	}
+	if expected, ok := argCountMismatch(fact.Format, len(call.Args)-msgIndex-1); ok {
+		pass.ReportRangef(call, "%s called with %d arguments but format %q expects %d", types.ExprString(call.Fun), len(call.Args)-msgIndex-1, fact.Format, expected)
+	}
+}
+
+// checkExpandArgs checks the arguments of Expand and ExpandSafe, which format the message immediately, also without arguments.
+func checkExpandArgs(pass *analysis.Pass, infos *TemplateInfos, call *ast.CallExpr) {
+	if call.Ellipsis.IsValid() {
+		return
+	}
+	fact := infos.withMessage(TemplateFact{}, call, 0)
+	if !fact.HasFormat {
+		return
+	}
+	if expected, ok := argCountMismatch(fact.Format, len(call.Args)-1); ok {
+		pass.ReportRangef(call, "%s called with %d arguments but format %q expects %d", types.ExprString(call.Fun), len(call.Args)-1, fact.Format, expected)
+	}
+}
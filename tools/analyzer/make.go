@@ -0,0 +1,74 @@
+package analyzer
+
+import (
+	"go/ast"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+)
+
+// MakeCall reports templates that are used like errors without calling Make() and discarded results of mutator functions.
+var MakeCall = &analysis.Analyzer{
+	Name:     "errmakecall",
+	Doc:      "report templates used as errors without Make() and discarded mutator results\n\nTemplates are no errors and must be instantiated using Make() before they are returned or panicked. All mutator functions of templates and errors return a modified copy, so discarding the result has no effect.",
+	Run:      runMakeCall,
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+}
+
+// sideEffectMethods return their receiver but are called for their side effects.
+var sideEffectMethods = map[string]bool{
+	"Register":   true,
+	"RegisterIn": true,
+}
+
+func runMakeCall(pass *analysis.Pass) (interface{}, error) {
+	lib := findLibrary(pass.Pkg)
+	if lib == nil {
+		return nil, nil
+	}
+
+	nodes := []ast.Node{(*ast.ReturnStmt)(nil), (*ast.ExprStmt)(nil), (*ast.CallExpr)(nil)}
+	inspectorOf(pass).WithStack(nodes, func(n ast.Node, push bool, stack []ast.Node) bool {
+		if !push {
+			return true
+		}
+		switch n := n.(type) {
+		case *ast.ReturnStmt:
+			sig := enclosingFunc(pass.TypesInfo, stack)
+			if sig == nil || sig.Results().Len() != len(n.Results) {
+				return true
+			}
+			for i, result := range n.Results {
+				resultType := sig.Results().At(i).Type()
+				if lib.isTemplate(pass.TypesInfo.TypeOf(result)) && types.IsInterface(resultType) {
+					pass.ReportRangef(result, "template %s returned as %s without calling Make()", types.ExprString(result), resultType)
+				}
+			}
+
+		case *ast.CallExpr:
+			if id, ok := ast.Unparen(n.Fun).(*ast.Ident); ok && len(n.Args) == 1 {
+				if _, ok := pass.TypesInfo.Uses[id].(*types.Builtin); ok && id.Name == "panic" && lib.isTemplate(pass.TypesInfo.TypeOf(n.Args[0])) {
+					pass.ReportRangef(n.Args[0], "template %s panicked without calling Make()", types.ExprString(n.Args[0]))
+				}
+			}
+
+		case *ast.ExprStmt:
+			call, ok := ast.Unparen(n.X).(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			resultType := pass.TypesInfo.TypeOf(call)
+			if !lib.isTemplate(resultType) && !lib.isError(resultType) {
+				return true
+			}
+			if _, name, ok := lib.method(pass.TypesInfo, call); ok && !sideEffectMethods[name] {
+				pass.ReportRangef(call, "result of %s() is discarded: mutator functions return a modified copy", name)
+			} else if lib.isFunc(pass.TypesInfo, call, "New", "NewTyped", "Wrap", "WrapT") {
+				pass.ReportRangef(call, "result of %s is discarded", types.ExprString(call.Fun))
+			}
+		}
+		return true
+	})
+	return nil, nil
+}
@@ -0,0 +1,60 @@
+package analyzer
+
+import (
+	"go/ast"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+)
+
+// MsgSafe reports calls to Error.Msg() that silently remove the safe flag.
+var MsgSafe = &analysis.Analyzer{
+	Name:     "errmsgsafe",
+	Doc:      "report Msg() calls that silently clear the safe flag of an error\n\nMsg() on an Error replaces the message and marks it as unsafe, so a safe error (Safe(), API() or a safe template) suddenly responds with the generic safe message. The call is not reported if the chain continues with Safe().",
+	Run:      runMsgSafe,
+	Requires: []*analysis.Analyzer{inspect.Analyzer, Templates},
+}
+
+func runMsgSafe(pass *analysis.Pass) (interface{}, error) {
+	infos := pass.ResultOf[Templates].(*TemplateInfos)
+	lib := infos.lib
+	if lib == nil {
+		return nil, nil
+	}
+
+	nodes := []ast.Node{(*ast.CallExpr)(nil)}
+	inspectorOf(pass).WithStack(nodes, func(n ast.Node, push bool, stack []ast.Node) bool {
+		if !push {
+			return true
+		}
+		call := n.(*ast.CallExpr)
+		recv, name, ok := lib.method(pass.TypesInfo, call)
+		if !ok || name != "Msg" || !lib.isError(pass.TypesInfo.TypeOf(recv)) {
+			return true
+		}
+		if fact, ok := infos.eval(recv); !ok || !fact.Safe || chainCallsSafe(stack) {
+			return true
+		}
+		pass.ReportRangef(call, "Msg() clears the safe flag of %s: call Safe() afterwards if the new message is safe", types.ExprString(recv))
+		return true
+	})
+	return nil, nil
+}
+
+// chainCallsSafe returns true if the call on top of the stack is followed by Safe() or ExpandSafe() in a method chain.
+func chainCallsSafe(stack []ast.Node) bool {
+	for i := len(stack) - 1; i >= 2; i -= 2 {
+		sel, ok := stack[i-1].(*ast.SelectorExpr)
+		if !ok || sel.X != stack[i] {
+			return false
+		}
+		if _, ok := stack[i-2].(*ast.CallExpr); !ok {
+			return false
+		}
+		if sel.Sel.Name == "Safe" || sel.Sel.Name == "ExpandSafe" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,47 @@
+package analyzer
+
+import (
+	"go/ast"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+)
+
+// TemplateScope reports templates that are created inside of functions.
+var TemplateScope = &analysis.Analyzer{
+	Name:     "errtemplatescope",
+	Doc:      "report calls to errors.New and errors.NewTyped inside of functions\n\nTemplates define error types and should be declared once at package level. Creating templates inside of functions prevents comparison, registration and documentation. Test files are not checked.",
+	Run:      runTemplateScope,
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+}
+
+func runTemplateScope(pass *analysis.Pass) (interface{}, error) {
+	lib := findLibrary(pass.Pkg)
+	if lib == nil || pass.Pkg == lib.pkg {
+		return nil, nil
+	}
+
+	nodes := []ast.Node{(*ast.CallExpr)(nil)}
+	inspectorOf(pass).WithStack(nodes, func(n ast.Node, push bool, stack []ast.Node) bool {
+		if !push {
+			return true
+		}
+		call := n.(*ast.CallExpr)
+		if !lib.isFunc(pass.TypesInfo, call, "New", "NewTyped") {
+			return true
+		}
+		if strings.HasSuffix(pass.Fset.File(call.Pos()).Name(), "_test.go") {
+			return true
+		}
+		for _, parent := range stack {
+			switch parent.(type) {
+			case *ast.FuncDecl, *ast.FuncLit:
+				pass.ReportRangef(call, "template created inside of a function: declare templates at package level and use Make() to instantiate errors")
+				return true
+			}
+		}
+		return true
+	})
+	return nil, nil
+}
@@ -0,0 +1,31 @@
+package argcount
+
+import (
+	"analyzertest/lib"
+
+	"github.com/sbreitf1/errors"
+)
+
+var (
+	Invalid   = errors.New("Invalid value %q")
+	Derived   = Invalid.Derive("argcount.Derived")
+	Formatted = errors.New("Value %[2]d of %[1]s")
+	Applied   = errors.New("Count %d", 1)
+	Bad       = errors.New("Count %d and %d", 1) // want `errors.New called with 1 arguments but format "Count %d and %d" expects 2`
+)
+
+func args() {
+	_ = Invalid.Make().Args("x")
+	_ = Invalid.Make().Args("x", 1) // want `Args\(\) called with 2 arguments but format "Invalid value %q" expects 1`
+	_ = Derived.Args()              // want `Args\(\) called with 0 arguments but format "Invalid value %q" expects 1`
+	_ = Formatted.Args("a", 1)
+	_ = Formatted.Args("a")              // want `Args\(\) called with 1 arguments but format "Value %\[2\]d of %\[1\]s" expects 2`
+	_ = Applied.Args(2)                  // want `Args\(\) called on Applied with already applied arguments`
+	_ = lib.NotFound.Make().Args("user") // want `Args\(\) called with 1 arguments but format "Resource %s with id %d not found" expects 2`
+	_ = Invalid.Msg("Other %d%%").Args(1)
+	_ = Invalid.Msg("Other %d", 1, 2)              // want `Invalid.Msg called with 2 arguments but format "Other %d" expects 1`
+	_ = Invalid.Make().Expand("Expanded %s")       // want `Invalid.Make\(\).Expand called with 0 arguments but format "Expanded %s" expects 1`
+	_ = Invalid.Make().Expand("50%% done").Args(1) // want `Args\(\) called on Invalid.Make\(\).Expand\("50%% done"\) with already applied arguments`
+	values := []interface{}{"x", 1}
+	_ = Invalid.Args(values...)
+}
@@ -0,0 +1,13 @@
+module analyzertest
+
+go 1.26.0
+
+require github.com/sbreitf1/errors v0.0.0
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	github.com/stretchr/testify v1.3.0 // indirect
+)
+
+replace github.com/sbreitf1/errors => ../../..
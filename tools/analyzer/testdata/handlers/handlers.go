@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"analyzertest/lib"
+
+	"github.com/sbreitf1/errors"
+)
+
+type Context struct{}
+
+func (c *Context) AbortWithStatusJSON(code int, obj interface{}) {}
+
+func load(id int) (string, errors.Error) {
+	return "", lib.Internal.Make()
+}
+
+func Unhandled(c *Context) {
+	value, err := load(1) // want `error err is not passed to ToRequest or ToLog`
+	if err != nil {
+		return
+	}
+	_ = value
+}
+
+func Handled(c *Context) {
+	_, err := load(1)
+	if err != nil {
+		err.ToRequest(c)
+		return
+	}
+	_, err2 := load(2)
+	if err2 != nil {
+		reply(c, err2)
+	}
+}
+
+func Returned(c *Context) errors.Error {
+	_, err := load(1)
+	return err
+}
+
+func Nested(c *Context) {
+	func(c *Context) {
+		_, err := load(1) // want `error err is not passed to ToRequest or ToLog`
+		if err == nil {
+			return
+		}
+	}(c)
+}
+
+func NoHandler() {
+	_, err := load(1)
+	if err != nil {
+		return
+	}
+}
+
+func reply(r errors.RequestAborter, err errors.Error) {
+	err.ToRequest(r)
+}
@@ -0,0 +1,8 @@
+package lib
+
+import "github.com/sbreitf1/errors"
+
+var (
+	NotFound = errors.New("Resource %s with id %d not found").API(404, 1) // want NotFound:`template\("Resource %s with id %d not found", safe=true\)`
+	Internal = errors.NewTyped("lib.Internal", "Internal error")          // want Internal:`template\("Internal error", safe=false\)`
+)
@@ -0,0 +1,29 @@
+package makecall
+
+import "github.com/sbreitf1/errors"
+
+var Invalid = errors.New("Invalid value %q")
+
+func returnTemplate() errors.TypedError {
+	return Invalid // want `template Invalid returned as github.com/sbreitf1/errors.TypedError without calling Make\(\)`
+}
+
+func returnInterface() interface{} {
+	return Invalid.Args("x") // want `template Invalid.Args\("x"\) returned as interface{} without calling Make\(\)`
+}
+
+func returnMade() error {
+	return Invalid.Args("x").Make()
+}
+
+func panicTemplate() {
+	panic(Invalid) // want `template Invalid panicked without calling Make\(\)`
+}
+
+func discarded(err errors.Error) {
+	Invalid.Register()
+	Invalid.Safe()   // want `result of Safe\(\) is discarded`
+	err.Cause(nil)   // want `result of Cause\(\) is discarded`
+	errors.Wrap(nil) // want `result of errors.Wrap is discarded`
+	err.ToLog()
+}
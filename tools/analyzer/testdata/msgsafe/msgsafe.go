@@ -0,0 +1,10 @@
+package msgsafe
+
+import "analyzertest/lib"
+
+func msg() {
+	_ = lib.NotFound.Make().Msg("Other") // want `Msg\(\) clears the safe flag of lib.NotFound.Make\(\)`
+	_ = lib.NotFound.Make().Msg("Other").Args().Safe()
+	_ = lib.Internal.Make().Msg("Other")
+	_ = lib.NotFound.Msg("Other").Make()
+}
@@ -0,0 +1,19 @@
+package scope
+
+import "github.com/sbreitf1/errors"
+
+var (
+	Valid   = errors.New("Valid")
+	Wrapped = func() errors.Template {
+		return errors.NewTyped("scope.Wrapped", "Wrapped") // want `template created inside of a function`
+	}()
+)
+
+func inside() errors.Error {
+	t := errors.New("Local") // want `template created inside of a function`
+	return t.Make()
+}
+
+func derived() errors.Error {
+	return Valid.Derive("scope.Derived").Make()
+}
@@ -0,0 +1,92 @@
+package analyzer
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+)
+
+// Unhandled reports errors in HTTP handlers that are checked but neither sent to the client nor logged.
+var Unhandled = &analysis.Analyzer{
+	Name:     "errunhandled",
+	Doc:      "report errors in handlers that are not passed to ToRequest or ToLog\n\nA handler is a function with a parameter implementing errors.RequestAborter like *gin.Context. Local variables of type errors.Error that are only compared to nil are reported, because the request is neither aborted nor the error logged.",
+	Run:      runUnhandled,
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+}
+
+func runUnhandled(pass *analysis.Pass) (interface{}, error) {
+	lib := findLibrary(pass.Pkg)
+	if lib == nil || lib.aborter == nil {
+		return nil, nil
+	}
+
+	nodes := []ast.Node{(*ast.FuncDecl)(nil), (*ast.FuncLit)(nil)}
+	inspectorOf(pass).Preorder(nodes, func(n ast.Node) {
+		var fnType *ast.FuncType
+		var body *ast.BlockStmt
+		switch fn := n.(type) {
+		case *ast.FuncDecl:
+			fnType, body = fn.Type, fn.Body
+		case *ast.FuncLit:
+			fnType, body = fn.Type, fn.Body
+		}
+		if body == nil || !isHandler(pass, lib, fnType) {
+			return
+		}
+
+		// collect all local error variables and whether they are used other than for nil comparisons
+		handled := make(map[*types.Var]bool)
+		var order []*ast.Ident
+		ast.Inspect(body, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.FuncLit:
+				// nested handlers are checked separately
+				return !isHandler(pass, lib, n.Type)
+			case *ast.BinaryExpr:
+				if (n.Op == token.EQL || n.Op == token.NEQ) && (isNil(pass, n.X) || isNil(pass, n.Y)) {
+					return false
+				}
+			case *ast.Ident:
+				if v, ok := pass.TypesInfo.Defs[n].(*types.Var); ok && lib.isError(v.Type()) {
+					if _, known := handled[v]; !known {
+						handled[v] = false
+						order = append(order, n)
+					}
+				} else if v, ok := pass.TypesInfo.Uses[n].(*types.Var); ok {
+					if _, known := handled[v]; known {
+						handled[v] = true
+					}
+				}
+			}
+			return true
+		})
+
+		for _, id := range order {
+			if !handled[pass.TypesInfo.Defs[id].(*types.Var)] {
+				pass.ReportRangef(id, "error %s is not passed to ToRequest or ToLog", id.Name)
+			}
+		}
+	})
+	return nil, nil
+}
+
+// isHandler returns true if a parameter of the function implements errors.RequestAborter.
+func isHandler(pass *analysis.Pass, lib *library, fnType *ast.FuncType) bool {
+	if fnType.Params == nil {
+		return false
+	}
+	for _, field := range fnType.Params.List {
+		if t := pass.TypesInfo.TypeOf(field.Type); t != nil && types.Implements(t, lib.aborter) {
+			return true
+		}
+	}
+	return false
+}
+
+func isNil(pass *analysis.Pass, expr ast.Expr) bool {
+	tv, ok := pass.TypesInfo.Types[expr]
+	return ok && tv.IsNil()
+}
@@ -0,0 +1,69 @@
+// Command errgen generates error templates from a YAML or JSON catalog file.
+//
+// Usage:
+//
+//	errgen [-package name] [-o file] catalog.yaml
+//
+// The output file defaults to the catalog file name with suffix "_gen.go". The package name defaults to the package of the catalog file or $GOPACKAGE if not set. Use it in conjunction with go generate:
+//
+//	//go:generate go run github.com/sbreitf1/errors/tools/cmd/errgen errors.yaml
+//
+// Besides the template variables, a helper constructor with typed parameters is generated for every template with placeholders.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sbreitf1/errors"
+	"github.com/sbreitf1/errors/tools/errgen"
+)
+
+var (
+	packageFlag = flag.String("package", "", "name of the generated package (default from catalog file or $GOPACKAGE)")
+	outFlag     = flag.String("o", "", "output file (default <catalog>_gen.go)")
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: errgen [flags] catalog-file\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := run(flag.Arg(0)); err != nil {
+		fmt.Fprintf(os.Stderr, "errgen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(file string) errors.Error {
+	f, err := errgen.LoadFile(file)
+	if err != nil {
+		return err
+	}
+
+	pkg := *packageFlag
+	if pkg == "" && f.Package == "" {
+		pkg = os.Getenv("GOPACKAGE")
+	}
+
+	out := *outFlag
+	if out == "" {
+		out = strings.TrimSuffix(file, filepath.Ext(file)) + "_gen.go"
+	}
+	w, createErr := os.Create(out)
+	if createErr != nil {
+		return errgen.WriteError.Make().Cause(createErr)
+	}
+	defer w.Close()
+
+	return errgen.Generate(w, f, errgen.GenerateOptions{Package: pkg, Source: filepath.Base(file)})
+}
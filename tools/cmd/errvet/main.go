@@ -0,0 +1,13 @@
+// Command errvet checks packages for misuse of templates and errors and is run using go vet:
+//
+//	go vet -vettool=$(which errvet) ./...
+package main
+
+import (
+	"github.com/sbreitf1/errors/tools/analyzer"
+	"golang.org/x/tools/go/analysis/unitchecker"
+)
+
+func main() {
+	unitchecker.Main(analyzer.Analyzers...)
+}
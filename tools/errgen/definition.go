@@ -0,0 +1,83 @@
+package errgen
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/sbreitf1/errors"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	// LoadError denotes a catalog file that could not be read.
+	LoadError = errors.NewTyped("errgen.LoadError", "Unable to load catalog file %q")
+	// DefinitionError denotes an invalid template definition in a catalog file.
+	DefinitionError = errors.NewTyped("errgen.DefinitionError", "Invalid definition of template %q")
+	// WriteError denotes generated code that could not be written.
+	WriteError = errors.NewTyped("errgen.WriteError", "Unable to write generated code")
+)
+
+// File is the format of catalog files maintained outside of Go code and consumed by code generation.
+type File struct {
+	// Package is the name of the generated Go package.
+	Package string `json:"package,omitempty" yaml:"package,omitempty"`
+	// Templates contains all template definitions in declaration order.
+	Templates []Definition `json:"templates" yaml:"templates"`
+}
+
+// Definition describes a template to generate.
+type Definition struct {
+	// Name is the name of the template variable.
+	Name string `json:"name" yaml:"name"`
+	// Type is the explicit error type. The message is used as type if empty.
+	Type string `json:"type,omitempty" yaml:"type,omitempty"`
+	// Derive denotes the name of the parent template in the same file.
+	Derive string `json:"derive,omitempty" yaml:"derive,omitempty"`
+	// Message is the message format string. Derived templates inherit the parent message if empty.
+	Message string `json:"message,omitempty" yaml:"message,omitempty"`
+	// HTTPCode is the http response code. Zero denotes the default or inherited value.
+	HTTPCode int `json:"httpCode,omitempty" yaml:"httpCode,omitempty"`
+	// ErrCode is the api error code. Zero denotes the default or inherited value.
+	ErrCode int `json:"errCode,omitempty" yaml:"errCode,omitempty"`
+	// Safe declares an API error using Template.API, which also untracks the template.
+	Safe     bool `json:"safe,omitempty" yaml:"safe,omitempty"`
+	Trace    bool `json:"trace,omitempty" yaml:"trace,omitempty"`
+	Untrack  bool `json:"untrack,omitempty" yaml:"untrack,omitempty"`
+	Register bool `json:"register,omitempty" yaml:"register,omitempty"`
+	// Tags contains tags without value (null), string tags and integer tags.
+	Tags map[string]interface{} `json:"tags,omitempty" yaml:"tags,omitempty"`
+	// Doc is the documentation comment of the template variable.
+	Doc string `json:"doc,omitempty" yaml:"doc,omitempty"`
+	// Params maps named placeholders like {name} to Go types. Defaults to string.
+	Params map[string]string `json:"params,omitempty" yaml:"params,omitempty"`
+	// Args describes the positional format verbs. Names and types are derived from the verbs if empty.
+	Args []Param `json:"args,omitempty" yaml:"args,omitempty"`
+}
+
+// Param describes a typed parameter of a helper constructor.
+type Param struct {
+	Name string `json:"name" yaml:"name"`
+	Type string `json:"type,omitempty" yaml:"type,omitempty"`
+}
+
+// LoadFile reads a catalog file in YAML (.yaml, .yml) or JSON format.
+func LoadFile(file string) (File, errors.Error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return File{}, LoadError.Make().Args(file).Cause(err)
+	}
+
+	var f File
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &f)
+	default:
+		err = json.Unmarshal(data, &f)
+	}
+	if err != nil {
+		return File{}, LoadError.Make().Args(file).Cause(err)
+	}
+	return f, nil
+}
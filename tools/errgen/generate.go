@@ -0,0 +1,338 @@
+// Package errgen generates error templates from catalog files maintained outside of Go code.
+package errgen
+
+import (
+	"fmt"
+	"go/format"
+	"io"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/sbreitf1/errors"
+	"github.com/sbreitf1/errors/catalog"
+	"github.com/sbreitf1/errors/internal/msgformat"
+)
+
+// GenerateOptions control code generation.
+type GenerateOptions struct {
+	// Package overrides the package name of the catalog file.
+	Package string
+	// Source denotes the catalog file in the header of the generated code.
+	Source string
+}
+
+// defaultHTTPCode is the http response code of templates without explicit code.
+const defaultHTTPCode = 500
+
+var goKeywords = map[string]bool{
+	"break": true, "case": true, "chan": true, "const": true, "continue": true, "default": true, "defer": true,
+	"else": true, "fallthrough": true, "for": true, "func": true, "go": true, "goto": true, "if": true,
+	"import": true, "interface": true, "map": true, "package": true, "range": true, "return": true,
+	"select": true, "struct": true, "switch": true, "type": true, "var": true,
+}
+
+// Generate writes Go source code declaring a template variable for every definition and a helper constructor with typed parameters for every template with placeholders.
+func Generate(w io.Writer, f File, opts GenerateOptions) errors.Error {
+	pkg := opts.Package
+	if pkg == "" {
+		pkg = f.Package
+	}
+	if pkg == "" {
+		return DefinitionError.Make().Args("").StrCause("missing package name")
+	}
+
+	defs := make(map[string]*Definition, len(f.Templates))
+	for i := range f.Templates {
+		def := &f.Templates[i]
+		if !isIdentifier(def.Name) {
+			return DefinitionError.Make().Args(def.Name).StrCause("name is not a valid identifier")
+		}
+		if _, ok := defs[def.Name]; ok {
+			return DefinitionError.Make().Args(def.Name).StrCause("duplicate name")
+		}
+		defs[def.Name] = def
+	}
+
+	var sb strings.Builder
+	if opts.Source != "" {
+		fmt.Fprintf(&sb, "// Code generated by errgen from %s. DO NOT EDIT.\n\n", opts.Source)
+	} else {
+		sb.WriteString("// Code generated by errgen. DO NOT EDIT.\n\n")
+	}
+	fmt.Fprintf(&sb, "package %s\n\nimport (\n\t\"%s\"\n)\n\n", pkg, catalog.LibraryPath)
+
+	sb.WriteString("var (\n")
+	for _, def := range f.Templates {
+		chain, err := templateChain(def, defs)
+		if err != nil {
+			return err
+		}
+		writeDoc(&sb, "\t", def.Doc)
+		fmt.Fprintf(&sb, "\t%s = %s\n", def.Name, chain)
+	}
+	sb.WriteString(")\n")
+
+	for _, def := range f.Templates {
+		def, err := inherit(def.Name, defs, nil)
+		if err != nil {
+			return err
+		}
+		if err := writeHelper(&sb, def); err != nil {
+			return err
+		}
+	}
+
+	src, fmtErr := format.Source([]byte(sb.String()))
+	if fmtErr != nil {
+		return WriteError.Make().Cause(fmtErr)
+	}
+	if _, err := w.Write(src); err != nil {
+		return WriteError.Make().Cause(err)
+	}
+	return nil
+}
+
+func templateChain(def Definition, defs map[string]*Definition) (string, errors.Error) {
+	var sb strings.Builder
+	if def.Derive != "" {
+		if _, ok := defs[def.Derive]; !ok {
+			return "", DefinitionError.Make().Args(def.Name).StrCause("unknown parent %q", def.Derive)
+		}
+		errType := def.Type
+		if errType == "" {
+			errType = def.Name
+		}
+		fmt.Fprintf(&sb, "%s.Derive(%q)", def.Derive, errType)
+		// Msg directly after Derive defines the message of the derived type, so it can still be localized
+		if def.Message != "" {
+			fmt.Fprintf(&sb, ".Msg(%q)", def.Message)
+		}
+	} else if def.Message == "" {
+		return "", DefinitionError.Make().Args(def.Name).StrCause("missing message")
+	} else if def.Type != "" {
+		fmt.Fprintf(&sb, "errors.NewTyped(%q, %q)", def.Type, def.Message)
+	} else {
+		fmt.Fprintf(&sb, "errors.New(%q)", def.Message)
+	}
+
+	if def.Safe {
+		// API requires both codes, so zero values are resolved from the ancestors
+		resolved, err := inherit(def.Name, defs, nil)
+		if err != nil {
+			return "", err
+		}
+		httpCode := resolved.HTTPCode
+		if httpCode == 0 {
+			httpCode = defaultHTTPCode
+		}
+		fmt.Fprintf(&sb, ".API(%d, %d)", httpCode, resolved.ErrCode)
+	} else {
+		if def.HTTPCode != 0 {
+			fmt.Fprintf(&sb, ".HTTPCode(%d)", def.HTTPCode)
+		}
+		if def.ErrCode != 0 {
+			fmt.Fprintf(&sb, ".ErrCode(%d)", def.ErrCode)
+		}
+		if def.Untrack {
+			sb.WriteString(".Untrack()")
+		}
+	}
+	if def.Trace {
+		sb.WriteString(".Trace()")
+	}
+
+	tags := make([]string, 0, len(def.Tags))
+	for tag := range def.Tags {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	for _, tag := range tags {
+		switch val := def.Tags[tag].(type) {
+		case nil:
+			fmt.Fprintf(&sb, ".Tag(%q)", tag)
+		case string:
+			fmt.Fprintf(&sb, ".TagStr(%q, %q)", tag, val)
+		case int:
+			fmt.Fprintf(&sb, ".TagInt(%q, %d)", tag, val)
+		case float64:
+			if val != float64(int(val)) {
+				return "", DefinitionError.Make().Args(def.Name).StrCause("tag %q is not an integer", tag)
+			}
+			fmt.Fprintf(&sb, ".TagInt(%q, %d)", tag, int(val))
+		default:
+			return "", DefinitionError.Make().Args(def.Name).StrCause("unsupported value of tag %q", tag)
+		}
+	}
+
+	if def.Register {
+		sb.WriteString(".Register()")
+	}
+	return sb.String(), nil
+}
+
+// inherit returns a copy of the named definition with message, params, args and codes inherited from its ancestors.
+func inherit(name string, defs map[string]*Definition, visiting map[string]bool) (Definition, errors.Error) {
+	def := *defs[name]
+	if def.Derive == "" {
+		return def, nil
+	}
+	if visiting[name] {
+		return def, DefinitionError.Make().Args(name).StrCause("cyclic derivation")
+	}
+	if visiting == nil {
+		visiting = make(map[string]bool)
+	}
+	visiting[name] = true
+	if _, ok := defs[def.Derive]; !ok {
+		return def, DefinitionError.Make().Args(name).StrCause("unknown parent %q", def.Derive)
+	}
+	parent, err := inherit(def.Derive, defs, visiting)
+	if err != nil {
+		return def, err
+	}
+
+	if def.Message == "" {
+		def.Message = parent.Message
+		if len(def.Args) == 0 {
+			def.Args = parent.Args
+		}
+	}
+	if def.HTTPCode == 0 {
+		def.HTTPCode = parent.HTTPCode
+	}
+	if def.ErrCode == 0 {
+		def.ErrCode = parent.ErrCode
+	}
+	params := make(map[string]string, len(parent.Params)+len(def.Params))
+	for name, typ := range parent.Params {
+		params[name] = typ
+	}
+	for name, typ := range def.Params {
+		params[name] = typ
+	}
+	def.Params = params
+	return def, nil
+}
+
+// writeHelper writes a constructor function with typed parameters for all placeholders of the template message.
+func writeHelper(sb *strings.Builder, def Definition) errors.Error {
+	msg := def.Message
+	spec := msgformat.Parse(msg)
+	if spec.Problem != "" {
+		return DefinitionError.Make().Args(def.Name).StrCause("%s", spec.Problem)
+	}
+	verbs, ok := spec.Simple()
+	if !ok {
+		return DefinitionError.Make().Args(def.Name).StrCause("explicit argument indexes and '*' are not supported")
+	}
+	placeholders := msgformat.Placeholders(msg)
+	if len(verbs) == 0 && len(placeholders) == 0 {
+		return nil
+	}
+
+	args := def.Args
+	if len(args) == 0 {
+		for i, verb := range verbs {
+			args = append(args, Param{fmt.Sprintf("arg%d", i+1), verbType(verb)})
+		}
+	} else if len(args) != len(verbs) {
+		return DefinitionError.Make().Args(def.Name).StrCause("expected %d args but got %d", len(verbs), len(args))
+	}
+
+	used := make(map[string]bool)
+	var params, calls []string
+	for _, name := range placeholders {
+		typ := def.Params[name]
+		if typ == "" {
+			typ = "string"
+		}
+		ident := paramIdentifier(name, used)
+		params = append(params, ident+" "+typ)
+		calls = append(calls, fmt.Sprintf(".With(%q, %s)", name, ident))
+	}
+	var argIdents []string
+	for i, arg := range args {
+		typ := arg.Type
+		if typ == "" {
+			typ = verbType(verbs[i])
+		}
+		ident := paramIdentifier(arg.Name, used)
+		params = append(params, ident+" "+typ)
+		argIdents = append(argIdents, ident)
+	}
+	if len(argIdents) > 0 {
+		calls = append(calls, fmt.Sprintf(".Args(%s)", strings.Join(argIdents, ", ")))
+	}
+
+	fmt.Fprintf(sb, "\n// Make%s instantiates %s with typed placeholder values.\n", def.Name, def.Name)
+	fmt.Fprintf(sb, "func Make%s(%s) errors.Error {\n", def.Name, strings.Join(params, ", "))
+	fmt.Fprintf(sb, "\treturn %s.MakeDepth(1)%s\n}\n", def.Name, strings.Join(calls, ""))
+	return nil
+}
+
+func verbType(verb rune) string {
+	switch verb {
+	case 'd', 'b', 'o', 'O', 'x', 'X', 'c', 'U':
+		return "int"
+	case 'e', 'E', 'f', 'F', 'g', 'G':
+		return "float64"
+	case 't':
+		return "bool"
+	case 's', 'q':
+		return "string"
+	default:
+		return "interface{}"
+	}
+}
+
+// paramIdentifier converts a placeholder name to an unused lower camel case identifier.
+func paramIdentifier(name string, used map[string]bool) string {
+	var sb strings.Builder
+	upper := false
+	for i, c := range name {
+		if c == '.' || c == '-' {
+			upper = i > 0
+			continue
+		}
+		if upper {
+			c = unicode.ToUpper(c)
+			upper = false
+		} else if sb.Len() == 0 {
+			c = unicode.ToLower(c)
+		}
+		sb.WriteRune(c)
+	}
+
+	ident := sb.String()
+	if ident == "" {
+		ident = "arg"
+	}
+	for goKeywords[ident] || used[ident] || ident == "errors" {
+		ident += "_"
+	}
+	used[ident] = true
+	return ident
+}
+
+func isIdentifier(name string) bool {
+	if name == "" || goKeywords[name] {
+		return false
+	}
+	for i, c := range name {
+		if !(c == '_' || unicode.IsLetter(c) || (i > 0 && unicode.IsDigit(c))) {
+			return false
+		}
+	}
+	return true
+}
+
+func writeDoc(sb *strings.Builder, indent, doc string) {
+	doc = strings.TrimSpace(doc)
+	if doc == "" {
+		return
+	}
+	for _, line := range strings.Split(doc, "\n") {
+		fmt.Fprintf(sb, "%s// %s\n", indent, strings.TrimSpace(line))
+	}
+}
@@ -0,0 +1,62 @@
+package errgen
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/sbreitf1/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerate(t *testing.T) {
+	f, err := LoadFile("testdata/catalog.yaml")
+	errors.AssertNil(t, err)
+
+	var sb strings.Builder
+	errors.AssertNil(t, Generate(&sb, f, GenerateOptions{Source: "catalog.yaml"}))
+
+	expected, readErr := ioutil.ReadFile("testdata/catalog_gen.go.golden")
+	assert.NoError(t, readErr)
+	assert.Equal(t, string(expected), sb.String())
+}
+
+func TestGenerateJSON(t *testing.T) {
+	f, err := LoadFile("testdata/catalog.json")
+	errors.AssertNil(t, err)
+
+	var sb strings.Builder
+	errors.AssertNil(t, Generate(&sb, f, GenerateOptions{Package: "api"}))
+	src := sb.String()
+	assert.True(t, strings.Contains(src, "package api\n"))
+	assert.True(t, strings.Contains(src, `InvalidArgumentError = errors.New("Argument {name} is not valid").API(400, 20).TagInt("weight", 2)`))
+	assert.True(t, strings.Contains(src, "func MakeInvalidArgumentError(name string) errors.Error {\n\treturn InvalidArgumentError.MakeDepth(1).With(\"name\", name)\n}"))
+}
+
+func TestGenerateAPI(t *testing.T) {
+	var sb strings.Builder
+	errors.AssertNil(t, Generate(&sb, File{Package: "p", Templates: []Definition{
+		{Name: "Base", Message: "base", HTTPCode: 404, ErrCode: 1},
+		{Name: "Derived", Derive: "Base", ErrCode: 2, Safe: true},
+		{Name: "Plain", Message: "plain", Safe: true, Untrack: true},
+	}}, GenerateOptions{}))
+	src := sb.String()
+	assert.True(t, strings.Contains(src, `Derived = Base.Derive("Derived").API(404, 2)`+"\n"))
+	assert.True(t, strings.Contains(src, `Plain   = errors.New("plain").API(500, 0)`+"\n"))
+}
+
+func TestGenerateInvalid(t *testing.T) {
+	var sb strings.Builder
+	errors.Assert(t, DefinitionError, Generate(&sb, File{Templates: []Definition{{Name: "Foo", Message: "foo"}}}, GenerateOptions{}))
+	errors.Assert(t, DefinitionError, Generate(&sb, File{Package: "p", Templates: []Definition{{Name: "func", Message: "foo"}}}, GenerateOptions{}))
+	errors.Assert(t, DefinitionError, Generate(&sb, File{Package: "p", Templates: []Definition{{Name: "Foo"}}}, GenerateOptions{}))
+	errors.Assert(t, DefinitionError, Generate(&sb, File{Package: "p", Templates: []Definition{{Name: "Foo", Derive: "Bar"}}}, GenerateOptions{}))
+	errors.Assert(t, DefinitionError, Generate(&sb, File{Package: "p", Templates: []Definition{{Name: "Foo", Message: "%d", Args: []Param{{"a", ""}, {"b", ""}}}}}, GenerateOptions{}))
+	errors.Assert(t, DefinitionError, Generate(&sb, File{Package: "p", Templates: []Definition{{Name: "Foo", Message: "%[1]d"}}}, GenerateOptions{}))
+	errors.Assert(t, LoadError, loadMissingFile())
+}
+
+func loadMissingFile() errors.Error {
+	_, err := LoadFile("testdata/missing.yaml")
+	return err
+}
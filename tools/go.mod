@@ -0,0 +1,19 @@
+module github.com/sbreitf1/errors/tools
+
+go 1.26.0
+
+require (
+	github.com/sbreitf1/errors v0.0.0
+	github.com/stretchr/testify v1.3.0
+	golang.org/x/tools v0.50.0
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/mod v0.41.0 // indirect
+	golang.org/x/sync v0.23.0 // indirect
+)
+
+replace github.com/sbreitf1/errors => ../
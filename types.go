@@ -1,5 +1,11 @@
 package errors
 
+import (
+	"time"
+
+	"github.com/sbreitf1/errors/internal/msgformat"
+)
+
 // ErrorType represents the base type of an error regardless of the specific error message.
 type ErrorType string
 
@@ -12,6 +18,11 @@ type RequestAborter interface {
 	AbortWithStatusJSON(int, interface{})
 }
 
+// HeaderGetter is an optional extension of RequestAborter to read request headers and is compatible with *gin.Context.
+type HeaderGetter interface {
+	GetHeader(key string) string
+}
+
 // TypedError represents errors and templates that define an error type.
 type TypedError interface {
 	// GetType returns the type of the error that is used for comparison.
@@ -19,8 +30,21 @@ type TypedError interface {
 }
 
 type content struct {
+	// message contains the (unformatted) message.
 	message string
-	cause   Error
+	// spec describes the format verbs of message.
+	spec *msgformat.Spec
+	// args are applied to message on output. Nil if no args have been supplied yet.
+	args []interface{}
+	// fields contains values for named placeholders like {name} and are also printed to log.
+	fields map[string]interface{}
+	// templated denotes that message originates from the template definition and may be localized.
+	templated bool
+	// inherited denotes that message has been inherited by Derive, so a new message defines the message of the derived template instead of replacing it.
+	inherited bool
+	cause     Error
+	// foreign is the original error encapsulated by Wrap. It is returned by Unwrap to keep it accessible for errors.Is and errors.As.
+	foreign error
 }
 
 type flags struct {
@@ -28,14 +52,27 @@ type flags struct {
 	trace  bool
 	isSafe bool
 	tags   map[string]interface{}
+	// severity is derived from the HTTP code if SeverityDefault.
+	severity Severity
 }
 
 type trace struct {
 	id         string
 	stackTrace string
+	// stack contains the program counters of the call stack.
+	stack []uintptr
+	// wrapSite is the program counter of the call to Wrap if stack originates from the wrapped error. Zero otherwise.
+	wrapSite uintptr
+	// goroutine describes the execution context. Nil if not captured.
+	goroutine *Goroutine
+	createdAt time.Time
+	// metadata describes the process. Nil if not captured.
+	metadata *Metadata
 }
 
 type apiData struct {
 	httpCode int
 	errCode  int
+	// codeOwner is the error type of the template that set errCode. Derived templates share the code of their owner.
+	codeOwner ErrorType
 }